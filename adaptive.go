@@ -0,0 +1,74 @@
+package readerwriter
+
+import "time"
+
+// AdaptiveStats is the input of an AdaptivePolicy.
+type AdaptiveStats struct {
+	// PendingWrites is the number of writes of the writer
+	// portion since the last swap.
+	PendingWrites int
+	// LastSwapWait is the time the last swap waited
+	// for old Reader's, zero if it did not block.
+	LastSwapWait time.Duration
+	// ActiveReaders is the result of Writer.ActiveReaders.
+	ActiveReaders int64
+}
+
+// AdaptivePolicy decides whether the pending writes
+// should be published now, see WithAdaptiveSwap.
+type AdaptivePolicy func(AdaptiveStats) bool
+
+// WithAdaptiveSwap consults policy after every Set and SetIfChanged,
+// which changed the writer portion, and swaps if it reports true.
+// The swap is like Swap, including the copy and the other options.
+// While swaps are suspended, a swap requested by policy is deferred
+// to ResumeSwaps like any other Swap.
+//
+// policy runs on the writer goroutine.
+func WithAdaptiveSwap[T any](policy AdaptivePolicy) Option[T] {
+	return runtimeOption(func(w *Writer[T]) {
+		w.adaptivePolicy = policy
+	})
+}
+
+// DefaultAdaptivePolicy swaps after batch pending writes. Every
+// signal of contention, i.e. a blocked last swap or active Reader's,
+// doubles the required number of writes, so swaps become less
+// frequent while Reader's hold up the Writer.
+func DefaultAdaptivePolicy(batch int) AdaptivePolicy {
+	return func(s AdaptiveStats) bool {
+		want := batch
+		if s.LastSwapWait > 0 {
+			want *= 2
+		}
+		if s.ActiveReaders > 0 {
+			want *= 2
+		}
+		return s.PendingWrites >= want
+	}
+}
+
+// wrote records a write of the writer portion
+// and swaps if the adaptive policy says so.
+func (w *Writer[T]) wrote() {
+	w.pendingWrites++
+	if w.adaptivePolicy == nil {
+		return
+	}
+	s := AdaptiveStats{
+		PendingWrites: w.pendingWrites,
+		LastSwapWait:  time.Duration(w.lastSwapWait.Load()),
+		ActiveReaders: w.ActiveReaders(),
+	}
+	if w.adaptivePolicy(s) {
+		// while suspended, the swap is deferred to ResumeSwaps.
+		w.swap(true)
+	}
+}
+
+// publishedWrites records the writes since the
+// last swap for WritesPerSwap and resets them.
+func (w *Writer[T]) publishedWrites() {
+	w.stats.writesPerSwap.observe(int64(w.pendingWrites))
+	w.pendingWrites = 0
+}
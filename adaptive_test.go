@@ -0,0 +1,62 @@
+package readerwriter
+
+import "testing"
+
+func TestAdaptiveSwap(t *testing.T) {
+	w := New(0, 0, WithAdaptiveSwap[int](DefaultAdaptivePolicy(3)))
+	read := func() int {
+		r := w.Reader()
+		defer r.Done()
+		return r.Get()
+	}
+
+	w.Set(1)
+	w.Set(2)
+	if got := read(); got != 0 {
+		t.Fatalf("got %d before the batch is full, want 0", got)
+	}
+	w.Set(3)
+	if got := read(); got != 3 {
+		t.Fatalf("got %d after the batch is full, want 3", got)
+	}
+
+	r := w.Reader()
+	for i := 4; i <= 8; i++ {
+		w.Set(i)
+	}
+	if got := read(); got != 3 {
+		t.Fatalf("got %d while contended, want 3", got)
+	}
+	r.Done()
+
+	w.SuspendSwaps()
+	w.Set(9)
+	if got := read(); got != 3 {
+		t.Fatalf("got %d while suspended, want 3", got)
+	}
+	w.ResumeSwaps()
+	if got := read(); got != 9 {
+		t.Fatalf("got %d after resume, want the deferred 9", got)
+	}
+	w.Set(10)
+	w.Set(11)
+	w.Set(12)
+	if got := read(); got != 12 {
+		t.Fatalf("got %d, want 12", got)
+	}
+}
+
+func TestAdaptiveSwapSuspended(t *testing.T) {
+	w := New(0, 0, WithAdaptiveSwap[int](DefaultAdaptivePolicy(1)))
+	w.SuspendSwaps()
+	w.Set(42)
+	if got := w.CurrentGeneration(); got != 0 {
+		t.Fatalf("swapped while suspended, generation %d", got)
+	}
+	w.ResumeSwaps()
+	r := w.Reader()
+	defer r.Done()
+	if got := r.Get(); got != 42 {
+		t.Fatalf("got %d after ResumeSwaps, want 42", got)
+	}
+}
@@ -0,0 +1,131 @@
+package readerwriter
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrTooManyReaders is returned by Writer.ReaderContext, if the
+// limit of WithMaxConcurrentReaders is reached and blocking is disabled.
+var ErrTooManyReaders = errors.New("too many readers")
+
+// ErrNotAdmitting is returned by Writer.ReaderContext
+// between StopAdmitting and ResumeAdmitting.
+var ErrNotAdmitting = errors.New("not admitting new readers")
+
+const messageTooManyReaders = "too many readers"
+
+// WithMaxConcurrentReaders limits the number of Reader's, which are
+// not done yet, to n. This bounds the number of Reader's a buggy or
+// adversarial read load can hold on to.
+//
+// If block is true, acquiring a Reader at the limit waits until
+// another Reader is done, ReaderContext until ctx is done.
+// Otherwise acquiring fails fast: TryReader reports false,
+// ReaderContext returns ErrTooManyReaders and Reader panics.
+// ReadOrStale returns the cached value in both cases.
+func WithMaxConcurrentReaders[T any](n int64, block bool) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.readerSlots = make(chan struct{}, n)
+		w.blockOnMaxReaders = block
+	})
+}
+
+// admit takes a slot of WithMaxConcurrentReaders for a new Reader.
+func (w *Writer[T]) admit() {
+	if w.readerSlots == nil {
+		return
+	}
+	if w.blockOnMaxReaders {
+		w.readerSlots <- struct{}{}
+		return
+	}
+	if !w.tryAdmit() {
+		panic(messageTooManyReaders)
+	}
+}
+
+func (w *Writer[T]) admitContext(ctx context.Context) error {
+	if w.readerSlots == nil {
+		return nil
+	}
+	if !w.blockOnMaxReaders {
+		if !w.tryAdmit() {
+			return ErrTooManyReaders
+		}
+		return nil
+	}
+	select {
+	case w.readerSlots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *Writer[T]) tryAdmit() bool {
+	if w.readerSlots == nil {
+		return true
+	}
+	select {
+	case w.readerSlots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// release returns the slot taken by admit.
+func (w *Writer[T]) release() {
+	if w.readerSlots != nil {
+		<-w.readerSlots
+	}
+}
+
+// StopAdmitting prevents new Reader's until ResumeAdmitting,
+// e.g. to get a window without any Reader's for maintenance
+// together with DrainCurrent. Reader's acquired before are not
+// affected. Once StopAdmitting returns, every Reader is either
+// counted by ActiveReaders and DrainCurrent or not admitted.
+//
+// Reader and ReaderForRequest block until ResumeAdmitting, so
+// forgetting ResumeAdmitting stalls all of them. ReaderContext
+// returns ErrNotAdmitting instead, TryReader reports false and
+// ReadOrStale returns the cached value.
+//
+// Calling StopAdmitting is threadsafe.
+func (w *Writer[T]) StopAdmitting() {
+	w.admittingMu.Lock()
+	defer w.admittingMu.Unlock()
+	if w.resumeAdmitting == nil {
+		w.resumeAdmitting = make(chan struct{})
+	}
+	w.notAdmitting.Store(true)
+}
+
+// ResumeAdmitting admits new Reader's again
+// and wakes up the blocked ones.
+//
+// Calling ResumeAdmitting is threadsafe.
+func (w *Writer[T]) ResumeAdmitting() {
+	w.admittingMu.Lock()
+	defer w.admittingMu.Unlock()
+	if w.closed.Load() {
+		return
+	}
+	w.notAdmitting.Store(false)
+	if w.resumeAdmitting != nil {
+		close(w.resumeAdmitting)
+		w.resumeAdmitting = nil
+	}
+}
+
+// waitAdmitting blocks until ResumeAdmitting.
+func (w *Writer[T]) waitAdmitting() {
+	w.admittingMu.Lock()
+	resume := w.resumeAdmitting
+	w.admittingMu.Unlock()
+	if resume != nil {
+		<-resume
+	}
+}
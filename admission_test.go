@@ -0,0 +1,80 @@
+package readerwriter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMaxConcurrentReaders(t *testing.T) {
+	w := New(0, 0, WithMaxConcurrentReaders[int](1, false))
+	r := w.Reader()
+	if _, ok := w.TryReader(); ok {
+		t.Fatal("TryReader exceeded the limit")
+	}
+	if _, err := w.ReaderContext(context.Background()); !errors.Is(err, ErrTooManyReaders) {
+		t.Fatalf("got %v, want %v", err, ErrTooManyReaders)
+	}
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("Reader exceeded the limit")
+			}
+		}()
+		w.Reader()
+	}()
+	r.Done()
+
+	r, ok := w.TryReader()
+	if !ok {
+		t.Fatal("TryReader failed below the limit")
+	}
+	r.Done()
+}
+
+func TestMaxConcurrentReadersBlocking(t *testing.T) {
+	w := New(0, 0, WithMaxConcurrentReaders[int](1, true))
+	r := w.Reader()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if _, err := w.ReaderContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		defer close(acquired)
+		w.Reader().Done()
+	}()
+	r.Done()
+	<-acquired
+}
+
+func TestStopAdmitting(t *testing.T) {
+	w := New(0, 0)
+	held := w.Reader()
+	w.StopAdmitting()
+	if _, ok := w.TryReader(); ok {
+		t.Fatal("TryReader admitted a reader")
+	}
+	if _, err := w.ReaderContext(context.Background()); !errors.Is(err, ErrNotAdmitting) {
+		t.Fatalf("got %v, want %v", err, ErrNotAdmitting)
+	}
+	held.Done()
+	w.DrainCurrent()
+
+	acquired := make(chan struct{})
+	go func() {
+		defer close(acquired)
+		w.Reader().Done()
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("Reader admitted while stopped")
+	case <-time.After(10 * time.Millisecond):
+	}
+	w.ResumeAdmitting()
+	<-acquired
+}
@@ -0,0 +1,70 @@
+package readerwriter
+
+import (
+	"runtime"
+	"time"
+)
+
+// BackoffStrategy is called before retrying to acquire a Reader,
+// while a Swap is in progress, see WithBackoff. attempt starts at
+// zero for every acquisition. clock is the TimeSource of the Writer
+// (see WithTimeSource), which must be used for sleeping. Backoff is
+// called concurrently by all Reader's, so it must be threadsafe.
+type BackoffStrategy interface {
+	Backoff(attempt int, clock TimeSource)
+}
+
+// BackoffFunc is a BackoffStrategy implemented by a function.
+type BackoffFunc func(attempt int, clock TimeSource)
+
+func (f BackoffFunc) Backoff(attempt int, clock TimeSource) {
+	f(attempt, clock)
+}
+
+// defaultSpins is the number of attempts DefaultBackoff spins.
+const defaultSpins = 64
+
+// singleProc caches runtime.GOMAXPROCS(0) == 1 for DefaultBackoff,
+// because GOMAXPROCS takes a global scheduler lock.
+var singleProc = runtime.GOMAXPROCS(0) == 1
+
+var (
+	// DefaultBackoff spins for a few attempts and yields the
+	// processor afterwards. Spinning is pointless with a single P,
+	// because the Writer cannot make progress in the meantime,
+	// so it always yields then. GOMAXPROCS is checked once at
+	// program start, later changes are not taken into account.
+	DefaultBackoff BackoffStrategy = BackoffFunc(func(attempt int, _ TimeSource) {
+		if attempt >= defaultSpins || singleProc {
+			runtime.Gosched()
+		}
+	})
+	// SpinBackoff retries immediately.
+	SpinBackoff BackoffStrategy = BackoffFunc(func(int, TimeSource) {})
+	// YieldBackoff yields the processor before every retry.
+	YieldBackoff BackoffStrategy = BackoffFunc(func(int, TimeSource) { runtime.Gosched() })
+)
+
+// ExponentialBackoff sleeps min with the clock of the Writer before
+// the first retry and doubles the duration for every further attempt,
+// up to max.
+func ExponentialBackoff(min, max time.Duration) BackoffStrategy {
+	return BackoffFunc(func(attempt int, clock TimeSource) {
+		d := min
+		for i := 0; i < attempt && d < max; i++ {
+			d *= 2
+		}
+		if d > max {
+			d = max
+		}
+		clock.Sleep(d)
+	})
+}
+
+// WithBackoff replaces DefaultBackoff, which is used while
+// retrying to acquire a Reader during a Swap.
+func WithBackoff[T any](strategy BackoffStrategy) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.backoff = strategy
+	})
+}
@@ -0,0 +1,17 @@
+package readerwriter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExponentialBackoff(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	b := ExponentialBackoff(time.Second, 3*time.Second)
+	b.Backoff(0, clock)
+	b.Backoff(1, clock)
+	b.Backoff(5, clock)
+	if got, want := clock.Now(), time.Unix(6, 0); !got.Equal(want) {
+		t.Fatalf("clock at %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,44 @@
+package readerwriter
+
+import (
+	"encoding"
+	"fmt"
+)
+
+// MarshalBinary encodes the published value, if T implements
+// encoding.BinaryMarshaler, e.g. to persist a snapshot.
+//
+// It holds a Reader while encoding, so a slow encoding
+// stalls a concurrent Swap.
+//
+// Calling MarshalBinary is threadsafe.
+func (w *Writer[T]) MarshalBinary() ([]byte, error) {
+	r := w.Reader()
+	defer r.Done()
+	m, ok := any(r.Get()).(encoding.BinaryMarshaler)
+	if !ok {
+		var zero T
+		return nil, fmt.Errorf("%T does not implement encoding.BinaryMarshaler", zero)
+	}
+	return m.MarshalBinary()
+}
+
+// UnmarshalBinary decodes data into a new value, if *T implements
+// encoding.BinaryUnmarshaler. The value replaces the writer portion
+// and is published like with Swap. The error of a rejected swap
+// (see SwapE) is returned as well.
+func (w *Writer[T]) UnmarshalBinary(data []byte) error {
+	var v T
+	u, ok := any(&v).(encoding.BinaryUnmarshaler)
+	if !ok {
+		return fmt.Errorf("%T does not implement encoding.BinaryUnmarshaler", &v)
+	}
+	if err := u.UnmarshalBinary(data); err != nil {
+		return err
+	}
+	w.lockWriter()
+	defer w.unlockWriter()
+	w.writerValue = v
+	_, err := w.swap(true)
+	return err
+}
@@ -0,0 +1,29 @@
+package readerwriter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBinary(t *testing.T) {
+	want := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	src := New(want, time.Time{})
+	b, err := src.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst := New(time.Time{}, time.Time{})
+	if err := dst.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	r := dst.Reader()
+	defer r.Done()
+	if got := r.Get(); !got.Equal(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if _, err := New(0, 0).MarshalBinary(); err == nil {
+		t.Fatal("MarshalBinary succeeded for int")
+	}
+}
@@ -0,0 +1,39 @@
+package readerwriter
+
+import "time"
+
+// TimeSource is the clock of a Writer, see WithTimeSource.
+type TimeSource interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+	// AfterFunc calls f in its own goroutine after d,
+	// unless stop is called before.
+	AfterFunc(d time.Duration, f func()) (stop func() bool)
+}
+
+// WithTimeSource replaces the real clock, which is used for all
+// timestamps, sleeps and timers of the Writer, e.g. to control the
+// time of WithMinSwapInterval or WithSwapDeadline in tests.
+func WithTimeSource[T any](clock TimeSource) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.clock = clock
+	})
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
+
+func (realClock) AfterFunc(d time.Duration, f func()) (stop func() bool) {
+	return time.AfterFunc(d, f).Stop
+}
+
+func (w *Writer[T]) since(t time.Time) time.Duration {
+	return w.clock.Now().Sub(t)
+}
@@ -0,0 +1,56 @@
+package readerwriter
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock only advances by calls to Sleep.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) AfterFunc(d time.Duration, f func()) (stop func() bool) {
+	return func() bool { return true }
+}
+
+func TestTimeSource(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	w := New(0, 0,
+		WithTimeSource[int](clock),
+		WithMinSwapInterval[int](time.Hour),
+	)
+	w.Swap()
+	w.Swap()
+	if got, want := clock.Now(), time.Unix(0, 0).Add(time.Hour); !got.Equal(want) {
+		t.Fatalf("clock at %v, want %v", got, want)
+	}
+}
+
+func TestReaderAge(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	w := New(0, 0, WithTimeSource[int](clock))
+	clock.Sleep(time.Second)
+	w.Swap()
+	clock.Sleep(time.Minute)
+
+	r := w.Reader()
+	defer r.Done()
+	if got := r.Age(); got != time.Minute {
+		t.Fatalf("got age %v, want %v", got, time.Minute)
+	}
+}
@@ -0,0 +1,42 @@
+package readerwriter
+
+import "errors"
+
+// ErrClosed is returned by Writer.ReaderContext
+// and Writer.TakePublished after TakePublished.
+var ErrClosed = errors.New("writer closed")
+
+const messageClosed = "writer closed"
+
+// TakePublished closes the Writer and returns the published value
+// for exclusive use, e.g. for a final dump during shutdown.
+// It stops admitting new Reader's and waits until the Reader's
+// of the published generation are done.
+//
+// Afterwards Reader and ReaderForRequest panic, ReaderContext returns
+// ErrClosed, TryReader reports false and ReadOrStale returns the
+// cached value. ResumeAdmitting has no effect. Calling TakePublished
+// again returns ErrClosed. The other writer methods must not be
+// called anymore.
+func (w *Writer[T]) TakePublished() (T, error) {
+	w.lockWriter()
+	defer w.unlockWriter()
+
+	w.admittingMu.Lock()
+	if w.closed.Load() {
+		w.admittingMu.Unlock()
+		var zero T
+		return zero, ErrClosed
+	}
+	w.closed.Store(true)
+	w.notAdmitting.Store(true)
+	if w.resumeAdmitting != nil {
+		// wake up the Reader's blocked by StopAdmitting, so they fail.
+		close(w.resumeAdmitting)
+		w.resumeAdmitting = nil
+	}
+	w.admittingMu.Unlock()
+
+	w.DrainCurrent()
+	return w.current.Load().v, nil
+}
@@ -0,0 +1,52 @@
+package readerwriter
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTakePublished(t *testing.T) {
+	w := New(1, 0)
+	w.Set(2)
+	w.Swap()
+
+	r := w.Reader()
+	taken := make(chan int)
+	go func() {
+		v, err := w.TakePublished()
+		if err != nil {
+			t.Error(err)
+		}
+		taken <- v
+	}()
+	for !w.closed.Load() {
+	}
+	select {
+	case <-taken:
+		t.Fatal("TakePublished returned with an active Reader")
+	default:
+	}
+	r.Done()
+	if v := <-taken; v != 2 {
+		t.Fatalf("got %d, want 2", v)
+	}
+
+	if _, err := w.TakePublished(); err != ErrClosed {
+		t.Fatalf("got %v, want ErrClosed", err)
+	}
+	if _, err := w.ReaderContext(context.Background()); err != ErrClosed {
+		t.Fatalf("got %v, want ErrClosed", err)
+	}
+	if _, ok := w.TryReader(); ok {
+		t.Fatal("TryReader succeeded after TakePublished")
+	}
+	if v := w.ReadOrStale(0, -1); v != -1 {
+		t.Fatalf("got %d, want cached value", v)
+	}
+	defer func() {
+		if p := recover(); p != messageClosed {
+			t.Fatalf("got panic %v, want %q", p, messageClosed)
+		}
+	}()
+	w.Reader()
+}
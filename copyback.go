@@ -0,0 +1,72 @@
+package readerwriter
+
+// WithAsyncCopyBack runs the copy of the new reader portion to
+// the new writer portion (see NewWithCopy) on a new goroutine after
+// the old Reader's are done, instead of on the writer goroutine.
+//
+// The swap returns before the copy is finished. Every subsequent
+// writer method, including the next Swap, waits for the copy first,
+// because it needs the writer portion. So this improves the latency
+// of a swap only if the writer goroutine does other work in the
+// meantime, at the cost of a goroutine per swap.
+func WithAsyncCopyBack[T any]() Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.asyncCopyBack = true
+	})
+}
+
+// copyBack copies reader to the writer portion
+// with the registered copy function.
+func (w *Writer[T]) copyBack(reader T) {
+	if !w.asyncCopyBack {
+		w.copyBackNow(reader)
+		return
+	}
+	done := make(chan struct{})
+	w.copyBackDone = done
+	w.copyBackReader = reader
+	writer := w.writerValue
+	go func() {
+		defer close(done)
+		n := w.copy(writer, reader)
+		w.stats.totalBytesCopied.Add(uint64(n))
+	}()
+}
+
+func (w *Writer[T]) copyBackNow(reader T) {
+	n := w.copy(w.writerValue, reader)
+	w.stats.totalBytesCopied.Add(uint64(n))
+	if w.divergenceFn != nil {
+		w.checkDivergenceAfterSwap(reader, true)
+	}
+}
+
+// waitCopyBack waits for a copy of WithAsyncCopyBack. The divergence
+// check of the copy runs here, so it is on the writer goroutine.
+func (w *Writer[T]) waitCopyBack() {
+	if w.copyBackDone == nil {
+		return
+	}
+	<-w.copyBackDone
+	w.copyBackDone = nil
+	reader := w.copyBackReader
+	var zero T
+	w.copyBackReader = zero
+	if w.divergenceFn != nil {
+		w.checkDivergenceAfterSwap(reader, true)
+	}
+}
+
+// copyBackPending reports whether a copy of
+// WithAsyncCopyBack is still running.
+func (w *Writer[T]) copyBackPending() bool {
+	if w.copyBackDone == nil {
+		return false
+	}
+	select {
+	case <-w.copyBackDone:
+		return false
+	default:
+		return true
+	}
+}
@@ -0,0 +1,48 @@
+package readerwriter
+
+import "testing"
+
+func TestAsyncCopyBack(t *testing.T) {
+	release := make(chan struct{})
+	w := NewWithCopy([]int{0}, []int{0}, func(dst, src []int) {
+		<-release
+		copy(dst, src)
+	}, WithAsyncCopyBack[[]int]())
+
+	w.Get()[0] = 1
+	w.Swap()
+	if _, ok := w.TryGetWriter(); ok {
+		t.Fatal("TryGetWriter succeeded during the copy")
+	}
+	close(release)
+	if got := w.Get()[0]; got != 1 {
+		t.Fatalf("writer portion %d after the copy, want 1", got)
+	}
+	if got := w.Stats().TotalBytesCopied; got != 0 {
+		t.Fatalf("copied %d bytes, want 0", got)
+	}
+}
+
+func TestAsyncCopyBackDivergence(t *testing.T) {
+	writer := goroutineID()
+	calls := 0
+	forgetCopy := func(dst, src map[string]int) {}
+	w := NewWithCopy(map[string]int{}, map[string]int{}, forgetCopy,
+		WithAsyncCopyBack[map[string]int](),
+		WithWarnOnDivergence(
+			func(a, b map[string]int) bool { return len(a) == len(b) },
+			func(map[string]int, map[string]int) {
+				if goroutineID() != writer {
+					t.Error("divergence reported on another goroutine")
+				}
+				calls++
+			},
+		),
+	)
+	w.Get()["a"] = 1
+	w.Swap()
+	w.Get()
+	if calls != 1 {
+		t.Fatalf("got %d divergence reports, want 1", calls)
+	}
+}
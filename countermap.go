@@ -0,0 +1,53 @@
+package readerwriter
+
+// CounterMap is a map of counters with lock-free reads,
+// which are incremented in batches by the writer.
+//
+// Add increments the writer portion and records the increment.
+// Publish swaps the portions and applies the recorded increments to
+// the reclaimed map, so publishing costs time proportional to the
+// number of distinct keys added to since the last Publish.
+// Read sees the counts of the last Publish.
+//
+// Read is threadsafe, all other methods follow the rules
+// of the Writer.
+type CounterMap[K comparable] struct {
+	w       *Writer[map[K]int64]
+	pending map[K]int64
+}
+
+// NewCounterMap returns a new CounterMap with all counts zero.
+func NewCounterMap[K comparable]() *CounterMap[K] {
+	return &CounterMap[K]{
+		w:       New(make(map[K]int64), make(map[K]int64)),
+		pending: make(map[K]int64),
+	}
+}
+
+// Read returns the published count of key.
+//
+// Calling Read is threadsafe.
+func (m *CounterMap[K]) Read(key K) int64 {
+	r := m.w.Reader()
+	n := r.Get()[key]
+	r.Done()
+	return n
+}
+
+// Add adds delta to the count of key. The change is
+// visible to Read after the next Publish.
+func (m *CounterMap[K]) Add(key K, delta int64) {
+	m.w.Get()[key] += delta
+	m.pending[key] += delta
+}
+
+// Publish makes all increments since the last Publish visible to
+// Read and waits until all Read's of the previous counts are done.
+func (m *CounterMap[K]) Publish() {
+	m.w.Swap()
+	reclaimed := m.w.Get()
+	for key, delta := range m.pending {
+		reclaimed[key] += delta
+		delete(m.pending, key)
+	}
+}
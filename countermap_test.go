@@ -0,0 +1,27 @@
+package readerwriter
+
+import "testing"
+
+func TestCounterMap(t *testing.T) {
+	m := NewCounterMap[string]()
+	m.Add("a", 1)
+	m.Add("a", 2)
+	if got := m.Read("a"); got != 0 {
+		t.Fatalf("got %d before Publish, want 0", got)
+	}
+	m.Publish()
+	if got := m.Read("a"); got != 3 {
+		t.Fatalf("got %d, want 3", got)
+	}
+
+	m.Add("a", 1)
+	m.Add("b", -1)
+	m.Publish()
+	if a, b := m.Read("a"), m.Read("b"); a != 4 || b != -1 {
+		t.Fatalf("got %d and %d, want 4 and -1", a, b)
+	}
+	m.Publish()
+	if got := m.Read("a"); got != 4 {
+		t.Fatalf("got %d after an empty Publish, want 4", got)
+	}
+}
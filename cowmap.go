@@ -0,0 +1,67 @@
+package readerwriter
+
+// CoWMap is a map with lock-free reads, built on top of Writer.
+//
+// Writes are applied to the writer portion and recorded.
+// Publish swaps the portions and replays only the recorded
+// writes on the reclaimed map, so publishing costs time
+// proportional to the number of changes and not to the size
+// of the map.
+//
+// Get is threadsafe, all other methods follow the rules
+// of the Writer.
+type CoWMap[K comparable, V any] struct {
+	w       *Writer[map[K]V]
+	pending []cowMapOp[K, V]
+}
+
+type cowMapOp[K comparable, V any] struct {
+	key    K
+	value  V
+	delete bool
+}
+
+// NewCoWMap returns a new empty CoWMap.
+func NewCoWMap[K comparable, V any]() *CoWMap[K, V] {
+	return &CoWMap[K, V]{w: New(make(map[K]V), make(map[K]V))}
+}
+
+// Get returns the published value for key.
+//
+// Calling Get is threadsafe.
+func (m *CoWMap[K, V]) Get(key K) (V, bool) {
+	r := m.w.Reader()
+	v, ok := r.Get()[key]
+	r.Done()
+	return v, ok
+}
+
+// Set sets key to value. The change is visible
+// to Get after the next Publish.
+func (m *CoWMap[K, V]) Set(key K, value V) {
+	m.w.Get()[key] = value
+	m.pending = append(m.pending, cowMapOp[K, V]{key: key, value: value})
+}
+
+// Delete removes key. The change is visible
+// to Get after the next Publish.
+func (m *CoWMap[K, V]) Delete(key K) {
+	delete(m.w.Get(), key)
+	m.pending = append(m.pending, cowMapOp[K, V]{key: key, delete: true})
+}
+
+// Publish makes all changes since the last Publish visible to Get
+// and waits until all Get's of the previous version are done.
+func (m *CoWMap[K, V]) Publish() {
+	m.w.Swap()
+	reclaimed := m.w.Get()
+	for i, op := range m.pending {
+		if op.delete {
+			delete(reclaimed, op.key)
+		} else {
+			reclaimed[op.key] = op.value
+		}
+		m.pending[i] = cowMapOp[K, V]{}
+	}
+	m.pending = m.pending[:0]
+}
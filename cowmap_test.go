@@ -0,0 +1,34 @@
+package readerwriter
+
+import "testing"
+
+func TestCoWMap(t *testing.T) {
+	m := NewCoWMap[string, int]()
+	m.Set("foo", 1)
+	m.Set("bar", 2)
+	if _, ok := m.Get("foo"); ok {
+		t.Fatal("unpublished value visible")
+	}
+
+	m.Publish()
+	m.Delete("bar")
+	m.Set("foo", 3)
+	m.Publish()
+	m.Set("baz", 4)
+	m.Publish()
+
+	want := map[string]int{"foo": 3, "baz": 4}
+	for _, part := range []map[string]int{m.w.Get(), m.w.current.Load().v} {
+		if len(part) != len(want) {
+			t.Fatalf("got %v, want %v", part, want)
+		}
+		for k, v := range want {
+			if part[k] != v {
+				t.Fatalf("got %v, want %v", part, want)
+			}
+		}
+	}
+	if v, ok := m.Get("foo"); !ok || v != 3 {
+		t.Fatalf("foo: got %d, %t", v, ok)
+	}
+}
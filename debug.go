@@ -0,0 +1,258 @@
+package readerwriter
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"time"
+)
+
+// ReaderInfo describes an active Reader.
+type ReaderInfo struct {
+	// Generation is the generation of the Reader.
+	Generation uint64
+	// Stack is the stack trace of the goroutine that acquired the
+	// Reader. It is only captured with WithReaderTracking.
+	Stack []byte
+}
+
+// SwapStall is passed to the WithSwapDeadline hook.
+type SwapStall struct {
+	// Name is the name of the Writer, see WithName.
+	Name string
+	// Generation is the generation that cannot be drained.
+	Generation uint64
+	// Waited is how long the swap has been waiting.
+	Waited time.Duration
+	// ActiveReaders is the number of Reader's still holding the generation.
+	ActiveReaders int64
+	// Readers contains the still active Reader's of the generation,
+	// if WithReaderTracking is enabled.
+	Readers []ReaderInfo
+}
+
+// WithSwapDeadline calls fn once, if a swap has been waiting longer
+// than d for the Reader's of the previous generation. It does not
+// abort the swap, it only reports the stall, e.g. a leaked Reader.
+//
+// fn is called on a separate goroutine while the swap is still blocked.
+func WithSwapDeadline[T any](d time.Duration, fn func(SwapStall)) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.swapDeadline = d
+		w.swapDeadlineFn = fn
+	})
+}
+
+// WithReaderTracking records the stack trace of every acquired Reader
+// until it is done, which is reported by WithSwapDeadline.
+// This is expensive and is meant for debugging only.
+func WithReaderTracking[T any]() Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.trackReaders = true
+	})
+}
+
+// WithWriterDebug records the stack trace of the goroutine inside
+// a writer method, so the panic on multiple writers includes the
+// call site of the other writer. This is expensive and is meant
+// for debugging only.
+func WithWriterDebug[T any]() Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.writerDebug = true
+	})
+}
+
+func (w *Writer[T]) recordWriter() {
+	stack := debug.Stack()
+	w.writerStack.Store(&stack)
+}
+
+func (w *Writer[T]) multipleWritersDetected() {
+	if stack := w.writerStack.Load(); stack != nil {
+		panic(fmt.Sprintf("%s, other writer:\n%s", messageMultipleWritersDetected, *stack))
+	}
+	panic(messageMultipleWritersDetected)
+}
+
+func (w *Writer[T]) track(r *Reader[T]) {
+	info := ReaderInfo{Generation: r.current.generation.Load(), Stack: debug.Stack()}
+	w.trackedMu.Lock()
+	defer w.trackedMu.Unlock()
+	if w.tracked == nil {
+		w.tracked = make(map[uint64]ReaderInfo)
+	}
+	// the map must not reference the Reader,
+	// otherwise a leaked Reader is never collected.
+	w.nextTrackID++
+	r.trackID = w.nextTrackID
+	w.tracked[r.trackID] = info
+}
+
+func (w *Writer[T]) untrack(r *Reader[T]) {
+	w.trackedMu.Lock()
+	defer w.trackedMu.Unlock()
+	delete(w.tracked, r.trackID)
+}
+
+func (w *Writer[T]) readerInfo(r *Reader[T]) ReaderInfo {
+	w.trackedMu.Lock()
+	defer w.trackedMu.Unlock()
+	if info, ok := w.tracked[r.trackID]; ok {
+		return info
+	}
+	return ReaderInfo{Generation: r.current.generation.Load()}
+}
+
+func (w *Writer[T]) swapStall(c *current[T]) SwapStall {
+	stall := SwapStall{
+		Name:          w.name,
+		Generation:    c.generation.Load(),
+		ActiveReaders: c.readers.Load(),
+	}
+	if since := c.blockedSince.Load(); since != 0 {
+		stall.Waited = w.since(time.Unix(0, since))
+	}
+	w.trackedMu.Lock()
+	defer w.trackedMu.Unlock()
+	for _, info := range w.tracked {
+		if info.Generation == c.generation.Load() {
+			stall.Readers = append(stall.Readers, info)
+		}
+	}
+	return stall
+}
+
+// WithLeakDetection calls fn if a Reader is garbage collected
+// before Done was called. Such a Reader blocks the next Swap
+// forever. The stack trace in info is only set with
+// WithReaderTracking. Detection relies on finalizers, so it
+// happens at some point after the leak, if at all.
+//
+// fn runs on a separate goroutine.
+func WithLeakDetection[T any](fn func(info ReaderInfo)) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.leakFn = fn
+	})
+}
+
+// WithAutoDoneOnGC calls Done on behalf of a Reader that is garbage
+// collected before Done was called, so the Writer does not deadlock.
+// The leak is reported to the WithLeakDetection hook or logged.
+//
+// This is a last-resort safety net, not a substitute for calling
+// Done: the garbage collector might run much later or never,
+// so a Swap can still be blocked for an arbitrary amount of time.
+func WithAutoDoneOnGC[T any]() Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.autoDoneOnGC = true
+	})
+}
+
+func (w *Writer[T]) watchLeak(r *Reader[T]) {
+	runtime.SetFinalizer(r, func(r *Reader[T]) {
+		if r.done.Load() {
+			return
+		}
+		info := w.readerInfo(r)
+		if w.leakFn != nil {
+			w.leakFn(info)
+		} else {
+			log.Printf("readerwriter: %q: reader of generation %d leaked\n%s", w.name, info.Generation, info.Stack)
+		}
+		if w.autoDoneOnGC {
+			r.Done()
+		}
+	})
+}
+
+// WithReentrancyCheck logs a warning, if a goroutine acquires a
+// Reader while it already holds one of the same Writer, e.g. a read
+// section calling a function, which reads again and forgets Done.
+// A nested Reader of an older generation blocks the next Swap, which
+// might in turn wait for the goroutine itself.
+//
+// Goroutines are identified by parsing their stack trace, so this
+// is expensive and is meant for debugging only.
+func WithReentrancyCheck[T any]() Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.reentrancyCheck = true
+		w.trackGoroutines = true
+	})
+}
+
+// WithDeadlockCheck makes a swap panic instead of blocking forever,
+// if the writer goroutine itself holds a Reader of the generation,
+// which the swap would wait for. This typically is a Reader of
+// the writer goroutine, which was not released with Done.
+//
+// Goroutines are identified like with WithReentrancyCheck,
+// so this is expensive and is meant for debugging only.
+func WithDeadlockCheck[T any]() Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.deadlockCheck = true
+		w.trackGoroutines = true
+	})
+}
+
+func (w *Writer[T]) enterRead(r *Reader[T]) {
+	r.goroutine = goroutineID()
+	w.holdersMu.Lock()
+	defer w.holdersMu.Unlock()
+	if w.holders == nil {
+		w.holders = make(map[uint64][]*current[T])
+	}
+	held := w.holders[r.goroutine]
+	if w.reentrancyCheck && len(held) > 0 {
+		log.Printf("readerwriter: %q: goroutine %d acquired a reader while holding %d\n%s", w.name, r.goroutine, len(held), debug.Stack())
+	}
+	w.holders[r.goroutine] = append(held, r.current)
+}
+
+func (w *Writer[T]) exitRead(r *Reader[T]) {
+	w.holdersMu.Lock()
+	defer w.holdersMu.Unlock()
+	held := w.holders[r.goroutine]
+	for i, c := range held {
+		if c == r.current {
+			held = append(held[:i], held[i+1:]...)
+			break
+		}
+	}
+	if len(held) == 0 {
+		delete(w.holders, r.goroutine)
+	} else {
+		w.holders[r.goroutine] = held
+	}
+}
+
+// checkDeadlock panics with WithDeadlockCheck, if the
+// calling goroutine holds a Reader of old.
+func (w *Writer[T]) checkDeadlock(old *current[T]) {
+	if !w.deadlockCheck {
+		return
+	}
+	goroutine := goroutineID()
+	w.holdersMu.Lock()
+	defer w.holdersMu.Unlock()
+	for _, c := range w.holders[goroutine] {
+		if c == old {
+			panic(messageSwapWouldDeadlock)
+		}
+	}
+}
+
+// goroutineID parses the id of the calling goroutine
+// from the first line of its stack trace.
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+	b = bytes.TrimPrefix(b, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i >= 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
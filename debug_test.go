@@ -0,0 +1,138 @@
+package readerwriter
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSwapDeadline(t *testing.T) {
+	stalls := make(chan SwapStall, 1)
+	w := New(0, 1,
+		WithName[int]("test"),
+		WithSwapDeadline[int](time.Millisecond, func(s SwapStall) { stalls <- s }),
+		WithReaderTracking[int](),
+	)
+	r := w.Reader()
+	swapped := make(chan struct{})
+	go func() {
+		w.Swap()
+		close(swapped)
+	}()
+
+	s := <-stalls
+	r.Done()
+	<-swapped
+	if s.Name != "test" || s.Generation != 0 || s.ActiveReaders != 1 || len(s.Readers) != 1 {
+		t.Fatalf("got %+v", s)
+	}
+	if !bytes.Contains(s.Readers[0].Stack, []byte("TestSwapDeadline")) {
+		t.Fatalf("stack does not contain the call site:\n%s", s.Readers[0].Stack)
+	}
+}
+
+func TestAutoDoneOnGC(t *testing.T) {
+	leaks := make(chan ReaderInfo, 1)
+	w := New(0, 1,
+		WithLeakDetection[int](func(info ReaderInfo) { leaks <- info }),
+		WithAutoDoneOnGC[int](),
+	)
+	func() {
+		w.Reader() // leaked
+	}()
+
+	swapped := make(chan struct{})
+	go func() {
+		w.Swap()
+		close(swapped)
+	}()
+	for {
+		runtime.GC()
+		select {
+		case info := <-leaks:
+			if info.Generation != 0 {
+				t.Fatalf("got %+v", info)
+			}
+			<-swapped
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+}
+
+func TestWriterDebug(t *testing.T) {
+	w := New(0, 0, WithWriterDebug[int]())
+	p := w.BeginSwap()
+	defer p.Commit()
+
+	defer func() {
+		msg, _ := recover().(string)
+		if !strings.HasPrefix(msg, messageMultipleWritersDetected) || !strings.Contains(msg, "BeginSwap") {
+			t.Fatalf("unexpected panic %q", msg)
+		}
+	}()
+	w.Get()
+}
+
+func TestReentrancyCheck(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	w := New(0, 0, WithName[int]("test"), WithReentrancyCheck[int]())
+	r := w.Reader()
+	r.Done()
+	if buf.Len() != 0 {
+		t.Fatalf("unexpected warning %q", buf.String())
+	}
+
+	outer := w.Reader()
+	inner := w.Reader()
+	inner.Done()
+	outer.Done()
+	if !strings.Contains(buf.String(), `"test": goroutine`) {
+		t.Fatalf("missing warning, got %q", buf.String())
+	}
+
+	buf.Reset()
+	r = w.Reader()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Reader().Done()
+	}()
+	<-done
+	r.Done()
+	if buf.Len() != 0 {
+		t.Fatalf("warning for different goroutines %q", buf.String())
+	}
+}
+
+func TestDeadlockCheck(t *testing.T) {
+	w := New(0, 0, WithDeadlockCheck[int]())
+	r := w.Reader()
+	func() {
+		defer func() {
+			if p := recover(); p != messageSwapWouldDeadlock {
+				t.Fatalf("got panic %v, want %q", p, messageSwapWouldDeadlock)
+			}
+		}()
+		w.Swap()
+	}()
+	r.Done()
+	w.Swap()
+
+	r = w.Reader()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Reader().Done()
+		r.Done()
+	}()
+	<-done
+	w.Swap()
+}
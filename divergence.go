@@ -0,0 +1,42 @@
+package readerwriter
+
+// WithWarnOnDivergence helps to find a forgotten copy of the new
+// reader portion to the new writer portion after a swap.
+//
+// If a swap copied with the registered copy function (see NewWithCopy),
+// fn is called afterwards if equal(reader, writer) reports false.
+// Otherwise the caller is responsible for the copy, which usually
+// requires a Reader. So if no Reader was acquired between two swaps,
+// fn is called before the second one if the portions differ.
+// The latter is only a heuristic, e.g. replacing the writer portion
+// completely with Set is reported as well.
+//
+// The comparisons are potentially expensive, so this is meant for
+// development only. fn runs on the writer goroutine. With
+// WithAsyncCopyBack the check after the copy runs, once the next
+// writer method waited for the copy.
+func WithWarnOnDivergence[T any](equal func(a, b T) bool, fn func(reader, writer T)) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.divergenceEqual = equal
+		w.divergenceFn = fn
+	})
+}
+
+func (w *Writer[T]) checkDivergenceBeforeSwap() {
+	readerSeen := w.readerSinceSwap.Swap(false)
+	c := w.current.Load()
+	if c.generation.Load() == 0 || w.copiedLastSwap || readerSeen {
+		return
+	}
+	reader := c.v
+	if !w.divergenceEqual(reader, w.writerValue) {
+		w.divergenceFn(reader, w.writerValue)
+	}
+}
+
+func (w *Writer[T]) checkDivergenceAfterSwap(reader T, copied bool) {
+	w.copiedLastSwap = copied
+	if copied && !w.divergenceEqual(reader, w.writerValue) {
+		w.divergenceFn(reader, w.writerValue)
+	}
+}
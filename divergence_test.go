@@ -0,0 +1,40 @@
+package readerwriter
+
+import "testing"
+
+func TestWarnOnDivergence(t *testing.T) {
+	equal := func(a, b []int) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+	warnings := 0
+	w := New([]int{}, []int{}, WithWarnOnDivergence(equal, func(reader, writer []int) {
+		warnings++
+	}))
+
+	// correct usage with copy back
+	w.Set(append(w.Get(), 1))
+	w.Swap()
+	r := w.Reader()
+	w.Set(append(w.Get()[:0], r.Get()...))
+	r.Done()
+	w.Set(append(w.Get(), 2))
+	w.Swap()
+	if warnings != 0 {
+		t.Fatalf("got %d warnings for correct usage", warnings)
+	}
+
+	// forgotten copy back
+	w.Set(append(w.Get(), 3))
+	w.Swap()
+	if warnings != 1 {
+		t.Fatalf("got %d warnings, want 1", warnings)
+	}
+}
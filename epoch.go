@@ -0,0 +1,155 @@
+package readerwriter
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Epoch is an alternative to Writer, which coordinates Reader's and
+// the writer with an atomic epoch counter instead of a RWMutex:
+// Reader's announce their presence in a per-epoch counter and the
+// writer sleeps until the counter of the previous epoch drops to
+// zero, instead of spinning on a lock.
+//
+// The even and odd epochs use the two portions alternately.
+// All involved operations are atomics from sync/atomic, which are
+// sequentially consistent. A Reader increments the counter of the
+// epoch it loaded and loads the epoch again afterwards. If the epoch
+// is unchanged, the increment happened before the next Swap advances
+// the epoch, so that Swap observes the Reader when waiting for the
+// counter. Otherwise the Reader retracts the increment and retries
+// with the new epoch. It only retries if a Swap happened in between,
+// never because of a held lock.
+//
+// The API mirrors a subset of Writer with the same threadsafety.
+type Epoch[T any] struct {
+	epoch   atomic.Uint64
+	portion [2]epochPortion[T]
+
+	unsyncWriterCheck sync.Mutex
+}
+
+type epochPortion[T any] struct {
+	v       T
+	readers atomic.Int64
+	// draining is set while the writer waits for the readers.
+	draining atomic.Bool
+	// drained wakes up the writer, after the last reader is done.
+	drained chan struct{}
+}
+
+// EpochReader is the Reader of an Epoch. An EpochReader
+// is not threadsafe.
+type EpochReader[T any] struct {
+	p    *epochPortion[T]
+	done bool
+}
+
+// NewEpoch returns a new Epoch with the specified
+// reader and writer portion, which must not share memory.
+func NewEpoch[T any](reader, writer T) *Epoch[T] {
+	e := &Epoch[T]{}
+	e.portion[0].v = reader
+	e.portion[1].v = writer
+	for i := range e.portion {
+		e.portion[i].drained = make(chan struct{}, 1)
+	}
+	return e
+}
+
+func (e *Epoch[T]) lockWriter() {
+	if !e.unsyncWriterCheck.TryLock() {
+		panic(messageMultipleWritersDetected)
+	}
+}
+
+func (e *Epoch[T]) unlockWriter() {
+	e.unsyncWriterCheck.Unlock()
+}
+
+// Get returns the current writer portion. The returned value
+// should only be used until calling Swap.
+func (e *Epoch[T]) Get() T {
+	e.lockWriter()
+	defer e.unlockWriter()
+	return e.writer().v
+}
+
+// Set sets the current writer portion.
+func (e *Epoch[T]) Set(v T) (previous T) {
+	e.lockWriter()
+	defer e.unlockWriter()
+	p := e.writer()
+	previous = p.v
+	p.v = v
+	return previous
+}
+
+func (e *Epoch[T]) writer() *epochPortion[T] {
+	return &e.portion[(e.epoch.Load()+1)&1]
+}
+
+// Reader returns the current reader portion without taking a lock.
+//
+// Calling Reader is threadsafe.
+func (e *Epoch[T]) Reader() *EpochReader[T] {
+	for {
+		epoch := e.epoch.Load()
+		p := &e.portion[epoch&1]
+		p.readers.Add(1)
+		if e.epoch.Load() == epoch {
+			return &EpochReader[T]{p: p}
+		}
+		// a Swap advanced the epoch in between
+		// and might not have seen the increment.
+		p.done()
+	}
+}
+
+// Get returns the value of the current EpochReader.
+func (r *EpochReader[T]) Get() T {
+	if r.done {
+		panic(messageUsageOldReaderDetected)
+	}
+	return r.p.v
+}
+
+// Done must be called when finished reading,
+// so the writer can make progress.
+func (r *EpochReader[T]) Done() {
+	if r.done {
+		panic(messageUsageOldReaderDetected)
+	}
+	r.done = true
+	r.p.done()
+}
+
+func (p *epochPortion[T]) done() {
+	if p.readers.Add(-1) == 0 && p.draining.Load() {
+		select {
+		case p.drained <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Swap publishes the writer portion and waits until all
+// EpochReader's of the old reader portion are done, which
+// is the writer portion afterwards.
+func (e *Epoch[T]) Swap() {
+	e.lockWriter()
+	defer e.unlockWriter()
+
+	old := &e.portion[e.epoch.Add(1)&1^1]
+	old.draining.Store(true)
+	for old.readers.Load() != 0 {
+		<-old.drained
+	}
+	old.draining.Store(false)
+	// discard a wake-up of a reader that decremented the counter
+	// between the load and the store above.
+	select {
+	case <-old.drained:
+	default:
+	}
+}
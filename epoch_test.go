@@ -0,0 +1,61 @@
+package readerwriter
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestEpoch(t *testing.T) {
+	e := NewEpoch(0, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			last := 0
+			for last < 100 {
+				r := e.Reader()
+				v := r.Get()
+				r.Done()
+				if v < last {
+					t.Errorf("read %d after %d", v, last)
+					return
+				}
+				last = v
+			}
+		}()
+	}
+	for i := 1; i <= 100; i++ {
+		e.Set(i)
+		e.Swap()
+	}
+	wg.Wait()
+}
+
+func TestEpochSwapWaitsForReader(t *testing.T) {
+	e := NewEpoch(1, 2)
+	r := e.Reader()
+	swapped := make(chan struct{})
+	go func() {
+		defer close(swapped)
+		e.Swap()
+	}()
+	if got := r.Get(); got != 1 {
+		t.Fatalf("got %d, want 1", got)
+	}
+	r.Done()
+	<-swapped
+	if got := e.Get(); got != 1 {
+		t.Fatalf("writer portion %d, want 1", got)
+	}
+}
+
+func BenchmarkEpochReader(b *testing.B) {
+	e := NewEpoch(1, 2)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			e.Reader().Done()
+		}
+	})
+}
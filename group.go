@@ -0,0 +1,136 @@
+package readerwriter
+
+import "sync"
+
+// Group coordinates the swaps of several Writer's, possibly of
+// different types, so that the Reader's returned by Group.Readers
+// are consistent across all members.
+//
+// The guarantee is: the Reader's of a single call to Readers either
+// see the values published by a Group.Swap for all members or for
+// none of them. This only holds if the members are swapped
+// exclusively with Group.Swap.
+type Group struct {
+	mu      sync.RWMutex
+	members []groupMember
+}
+
+type groupMember interface {
+	key() any
+	// beginSwap publishes the writer portion
+	// like Writer.BeginSwap.
+	beginSwap() groupSwap
+	reader() interface{ Done() }
+}
+
+// groupSwap is the PendingSwap of a member.
+type groupSwap interface {
+	Commit()
+	Rollback() error
+}
+
+type groupWriter[T any] struct {
+	w *Writer[T]
+}
+
+func (m groupWriter[T]) key() any {
+	return m.w
+}
+
+func (m groupWriter[T]) beginSwap() groupSwap {
+	return m.w.BeginSwap()
+}
+
+func (m groupWriter[T]) reader() interface{ Done() } {
+	return m.w.Reader()
+}
+
+// NewGroup returns an empty Group.
+func NewGroup() *Group {
+	return &Group{}
+}
+
+// AddToGroup adds w to the members of g. It must not be
+// called concurrently with Group.Swap or Group.Readers.
+func AddToGroup[T any](g *Group, w *Writer[T]) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.members = append(g.members, groupWriter[T]{w})
+}
+
+// Swap publishes the writer portions of all members like
+// Writer.BeginSwap and finishes the swaps like PendingSwap.Commit
+// afterwards. Validators, dedup, intervals and suspension of the
+// members are not consulted.
+//
+// If a member panics, e.g. in its WithPublishTransform, the members
+// before it are rolled back, so no member is swapped. Members whose
+// new generation was already acquired by a Reader from Writer.Reader
+// are committed instead.
+//
+// Swap is a writer method of every member, so it must only
+// be called by the goroutine writing the members.
+func (g *Group) Swap() {
+	swaps := g.beginSwaps()
+
+	// waiting for the old Reader's must not block Readers.
+	for _, s := range swaps {
+		s.Commit()
+	}
+}
+
+func (g *Group) beginSwaps() (swaps []groupSwap) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	defer func() {
+		if p := recover(); p != nil {
+			for _, s := range swaps {
+				if s.Rollback() != nil {
+					s.Commit()
+				}
+			}
+			panic(p)
+		}
+	}()
+	swaps = make([]groupSwap, 0, len(g.members))
+	for _, m := range g.members {
+		swaps = append(swaps, m.beginSwap())
+	}
+	return swaps
+}
+
+// GroupReaders holds a Reader of every member of a Group,
+// see Group.Readers.
+type GroupReaders struct {
+	readers map[any]interface{ Done() }
+}
+
+// Readers acquires a Reader of every member. Publications of a
+// Group.Swap are excluded while acquiring, so the Reader's are
+// consistent in the sense described at Group. The Reader's are
+// accessed with ReaderOf and must be released with ReleaseAll.
+//
+// Calling Readers is threadsafe.
+func (g *Group) Readers() *GroupReaders {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	rs := &GroupReaders{readers: make(map[any]interface{ Done() }, len(g.members))}
+	for _, m := range g.members {
+		rs.readers[m.key()] = m.reader()
+	}
+	return rs
+}
+
+// ReaderOf returns the Reader of w, or nil
+// if w was not a member of the Group.
+func ReaderOf[T any](rs *GroupReaders, w *Writer[T]) *Reader[T] {
+	r, _ := rs.readers[w].(*Reader[T])
+	return r
+}
+
+// ReleaseAll calls Done on all Reader's.
+func (rs *GroupReaders) ReleaseAll() {
+	for _, r := range rs.readers {
+		r.Done()
+	}
+}
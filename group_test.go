@@ -0,0 +1,84 @@
+package readerwriter
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestGroupReaders(t *testing.T) {
+	counts := New(0, 0)
+	names := New("0", "0")
+	g := NewGroup()
+	AddToGroup(g, counts)
+	AddToGroup(g, names)
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				rs := g.Readers()
+				count := ReaderOf(rs, counts).Get()
+				name := ReaderOf(rs, names).Get()
+				rs.ReleaseAll()
+				if want := string(rune('0' + count%10)); name != want {
+					t.Errorf("inconsistent snapshot: %d and %q", count, name)
+					return
+				}
+			}
+		}()
+	}
+	for i := 1; i <= 100; i++ {
+		counts.Set(i)
+		names.Set(string(rune('0' + i%10)))
+		g.Swap()
+	}
+	close(done)
+	wg.Wait()
+
+	rs := g.Readers()
+	defer rs.ReleaseAll()
+	if r := ReaderOf(rs, New(0, 0)); r != nil {
+		t.Fatal("reader for a non-member")
+	}
+}
+
+func TestGroupSwapPanic(t *testing.T) {
+	counts := New(0, 0)
+	names := New("0", "0", WithPublishTransform(func(string) string { panic("transform") }))
+	g := NewGroup()
+	AddToGroup(g, counts)
+	AddToGroup(g, names)
+
+	counts.Set(1)
+	func() {
+		defer func() {
+			if p := recover(); p != "transform" {
+				t.Fatalf("got panic %v, want transform", p)
+			}
+		}()
+		g.Swap()
+	}()
+	if gen := counts.CurrentGeneration(); gen != 0 {
+		t.Fatalf("got generation %d, want 0", gen)
+	}
+
+	rs := g.Readers()
+	if v := ReaderOf(rs, counts).Get(); v != 0 {
+		t.Fatalf("got %d, want 0", v)
+	}
+	rs.ReleaseAll()
+	counts.Swap()
+	r := counts.Reader()
+	defer r.Done()
+	if v := r.Get(); v != 1 {
+		t.Fatalf("got %d, want 1", v)
+	}
+}
@@ -0,0 +1,105 @@
+package readerwriter
+
+import "sync"
+
+// WithHistory retains clones of the last n published values,
+// which are returned by Writer.History, e.g. to reconstruct what
+// Reader's saw recently while debugging.
+//
+// clone must return a deep copy, which does not share memory with
+// its argument, otherwise the history would refer to the portions
+// reused by the Writer. It runs on the writer goroutine for every
+// publication. The history needs memory for n values, see
+// WithMemoryBudget to bound it by size instead.
+func WithHistory[T any](n int, clone func(T) T) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.history.size = n
+		w.history.clone = clone
+	})
+}
+
+// WithMemoryBudget bounds the memory retained by WithHistory to
+// budget bytes, as reported by size for every value. The oldest
+// values are dropped until the history fits into the budget,
+// which is counted in Stats.BudgetDrops.
+//
+// Only the history is counted. The diffs buffered by SubscribeDiff
+// and the objects of Writer.Retire, which are kept until their
+// generation is drained, are not part of the budget; a subscriber
+// that stops reading blocks the writer instead, see SubscribeDiff.
+func WithMemoryBudget[T any](budget int, size func(T) int) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.history.budget = budget
+		w.history.sizeOf = size
+	})
+}
+
+type history[T any] struct {
+	mu     sync.Mutex
+	size   int
+	clone  func(T) T
+	budget int
+	sizeOf func(T) int
+
+	// values are ordered from the oldest to the newest.
+	values []historyValue[T]
+	bytes  int
+}
+
+type historyValue[T any] struct {
+	v     T
+	bytes int
+}
+
+// add retains a clone of v and reports
+// the number of dropped values.
+func (h *history[T]) add(v T) (dropped int) {
+	if h.size <= 0 {
+		return 0
+	}
+	e := historyValue[T]{v: h.clone(v)}
+	if h.sizeOf != nil {
+		e.bytes = h.sizeOf(e.v)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.values = append(h.values, e)
+	h.bytes += e.bytes
+	if len(h.values) > h.size {
+		h.drop()
+	}
+	for h.sizeOf != nil && h.bytes > h.budget && len(h.values) > 0 {
+		h.drop()
+		dropped++
+	}
+	return dropped
+}
+
+func (h *history[T]) drop() {
+	h.bytes -= h.values[0].bytes
+	n := copy(h.values, h.values[1:])
+	h.values[n] = historyValue[T]{}
+	h.values = h.values[:n]
+}
+
+// addHistory adds v to the history of WithHistory.
+func (w *Writer[T]) addHistory(v T) {
+	if dropped := w.history.add(v); dropped > 0 {
+		w.stats.budgetDrops.Add(uint64(dropped))
+	}
+}
+
+// History returns the last published values retained with
+// WithHistory, the oldest first. The values must not be modified.
+//
+// Calling History is threadsafe.
+func (w *Writer[T]) History() []T {
+	h := &w.history
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	values := make([]T, len(h.values))
+	for i, e := range h.values {
+		values[i] = e.v
+	}
+	return values
+}
@@ -0,0 +1,38 @@
+package readerwriter
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	clone := func(v []int) []int { return append([]int(nil), v...) }
+	w := NewWithCopy([]int{0}, []int{0}, func(dst, src []int) { copy(dst, src) },
+		WithHistory(3, clone))
+	if got, want := fmt.Sprint(w.History()), "[[0]]"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	for i := 1; i <= 4; i++ {
+		w.Get()[0] = i
+		w.Swap()
+	}
+	if got, want := fmt.Sprint(w.History()), "[[2] [3] [4]]"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+}
+
+func TestMemoryBudget(t *testing.T) {
+	clone := func(v string) string { return v }
+	size := func(v string) int { return len(v) }
+	w := New("", "", WithHistory(10, clone), WithMemoryBudget(4, size))
+	for _, v := range []string{"ab", "cd", "e"} {
+		w.Set(v)
+		w.Swap()
+	}
+	if got, want := fmt.Sprint(w.History()), "[cd e]"; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+	if got := w.Stats().BudgetDrops; got != 2 {
+		t.Fatalf("got %d drops, want 2", got)
+	}
+}
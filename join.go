@@ -0,0 +1,57 @@
+package readerwriter
+
+const messageNotInGroup = "writer is not a member of the group"
+
+// Joined is a read-only view combining the published values
+// of two Writer's, see Join.
+type Joined[A, B, R any] struct {
+	wa      *Writer[A]
+	wb      *Writer[B]
+	combine func(A, B) R
+	group   *Group
+}
+
+// Join returns a view of wa and wb, which is read by combining the
+// published values with combine, e.g. to join two tables.
+//
+// The values are two independent recent snapshots: a Swap of wb
+// might happen after the Reader of wa is acquired, so the view can
+// contain the new value of wb but the old one of wa, even if both
+// were swapped together by the writer. Use JoinGroup for a
+// coordinated view.
+func Join[A, B, R any](wa *Writer[A], wb *Writer[B], combine func(A, B) R) *Joined[A, B, R] {
+	return &Joined[A, B, R]{wa: wa, wb: wb, combine: combine}
+}
+
+// JoinGroup is like Join, but the Reader's are acquired with
+// Group.Readers of g, which wa and wb must be members of. Then the
+// view is consistent in the sense described at Group, if both are
+// swapped with Group.Swap only. Every Read acquires a Reader of all
+// members of g.
+func JoinGroup[A, B, R any](g *Group, wa *Writer[A], wb *Writer[B], combine func(A, B) R) *Joined[A, B, R] {
+	return &Joined[A, B, R]{wa: wa, wb: wb, combine: combine, group: g}
+}
+
+// Read calls fn with the combined published values. The Reader's
+// of both Writer's are held until fn returns, so the result of
+// combine may share memory with the published values, it must
+// not be used after fn returns.
+//
+// Calling Read is threadsafe.
+func (j *Joined[A, B, R]) Read(fn func(R)) {
+	if j.group != nil {
+		rs := j.group.Readers()
+		defer rs.ReleaseAll()
+		ra, rb := ReaderOf(rs, j.wa), ReaderOf(rs, j.wb)
+		if ra == nil || rb == nil {
+			panic(messageNotInGroup)
+		}
+		fn(j.combine(ra.Get(), rb.Get()))
+		return
+	}
+	ra := j.wa.Reader()
+	defer ra.Done()
+	rb := j.wb.Reader()
+	defer rb.Done()
+	fn(j.combine(ra.Get(), rb.Get()))
+}
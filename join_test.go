@@ -0,0 +1,48 @@
+package readerwriter
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestJoin(t *testing.T) {
+	users := New(map[int]string{1: "alice"}, map[int]string{})
+	roles := New(map[int]string{1: "admin"}, map[int]string{})
+	j := Join(users, roles, func(u, r map[int]string) string {
+		return fmt.Sprintf("%s:%s", u[1], r[1])
+	})
+
+	var got string
+	j.Read(func(v string) { got = v })
+	if got != "alice:admin" {
+		t.Fatalf("got %q, want %q", got, "alice:admin")
+	}
+	if n := users.ActiveReaders() + roles.ActiveReaders(); n != 0 {
+		t.Fatalf("got %d active readers, want 0", n)
+	}
+}
+
+func TestJoinGroup(t *testing.T) {
+	a, b := New(1, 1), New(10, 10)
+	g := NewGroup()
+	AddToGroup(g, a)
+	AddToGroup(g, b)
+	j := JoinGroup(g, a, b, func(x, y int) int { return x + y })
+
+	a.Set(2)
+	b.Set(20)
+	g.Swap()
+	j.Read(func(sum int) {
+		if sum != 22 {
+			t.Fatalf("got %d, want 22", sum)
+		}
+	})
+
+	other := New(0, 0)
+	defer func() {
+		if p := recover(); p != messageNotInGroup {
+			t.Fatalf("got panic %v, want %q", p, messageNotInGroup)
+		}
+	}()
+	JoinGroup(g, a, other, func(x, y int) int { return x + y }).Read(func(int) {})
+}
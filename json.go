@@ -0,0 +1,31 @@
+package readerwriter
+
+import "encoding/json"
+
+// MarshalJSON encodes the published value with encoding/json,
+// e.g. for a debug endpoint.
+//
+// It holds a Reader while encoding, so a slow encoding
+// stalls a concurrent Swap.
+//
+// Calling MarshalJSON is threadsafe.
+func (w *Writer[T]) MarshalJSON() ([]byte, error) {
+	r := w.Reader()
+	defer r.Done()
+	return json.Marshal(r.Get())
+}
+
+// UnmarshalJSON decodes data into a new value with encoding/json,
+// which replaces the writer portion and is published like with Swap.
+// The error of a rejected swap (see SwapE) is returned as well.
+func (w *Writer[T]) UnmarshalJSON(data []byte) error {
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	w.lockWriter()
+	defer w.unlockWriter()
+	w.writerValue = v
+	_, err := w.swap(true)
+	return err
+}
@@ -0,0 +1,26 @@
+package readerwriter
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSON(t *testing.T) {
+	w := New(map[string]int{"a": 1}, map[string]int{})
+	b, err := json.Marshal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(b), `{"a":1}`; got != want {
+		t.Fatalf("got %s, want %s", got, want)
+	}
+
+	if err := json.Unmarshal([]byte(`{"b":2}`), w); err != nil {
+		t.Fatal(err)
+	}
+	r := w.Reader()
+	defer r.Done()
+	if got := r.Get(); len(got) != 1 || got["b"] != 2 {
+		t.Fatalf("unexpected published value %v", got)
+	}
+}
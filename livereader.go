@@ -0,0 +1,46 @@
+package readerwriter
+
+// LiveReader is a long-lived Reader that follows the newest
+// generation. It is meant for consumers that poll periodically.
+//
+// A LiveReader holds on to a generation between calls to Get,
+// so a Swap waits at most until the next Get or Close.
+// Hence Get should be called regularly, otherwise use Reader.
+// A LiveReader is not threadsafe.
+type LiveReader[T any] struct {
+	w *Writer[T]
+	r *Reader[T]
+}
+
+// LiveReader returns a new LiveReader, which must be
+// closed when no longer used.
+//
+// Calling LiveReader is threadsafe.
+func (w *Writer[T]) LiveReader() *LiveReader[T] {
+	return &LiveReader[T]{w: w, r: w.Reader()}
+}
+
+// Get returns the value of the newest generation. If a Swap
+// happened since the last call, the superseded generation is
+// released and the current one acquired.
+//
+// The returned value is valid until the next call to Get or Close.
+func (l *LiveReader[T]) Get() T {
+	if l.r == nil {
+		panic(messageUsageOldReaderDetected)
+	}
+	if l.w.current.Load() != l.r.current {
+		l.r.Done()
+		l.r = l.w.Reader()
+	}
+	return l.r.Get()
+}
+
+// Close releases the generation held by the LiveReader.
+func (l *LiveReader[T]) Close() {
+	if l.r == nil {
+		panic(messageUsageOldReaderDetected)
+	}
+	l.r.Done()
+	l.r = nil
+}
@@ -0,0 +1,32 @@
+package readerwriter
+
+import (
+	"runtime"
+	"testing"
+)
+
+func TestLiveReader(t *testing.T) {
+	w := New(1, 2)
+	l := w.LiveReader()
+	if v := l.Get(); v != 1 {
+		t.Fatalf("got %d, want 1", v)
+	}
+
+	swapped := make(chan struct{})
+	go func() {
+		w.Swap()
+		close(swapped)
+	}()
+	for w.current.Load().v != 2 {
+		runtime.Gosched()
+	}
+	if v := l.Get(); v != 2 {
+		t.Fatalf("got %d, want 2", v)
+	}
+	<-swapped
+
+	l.Close()
+	if got := w.ActiveReaders(); got != 0 {
+		t.Fatalf("active readers after Close: %d", got)
+	}
+}
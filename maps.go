@@ -0,0 +1,35 @@
+package readerwriter
+
+// NewMap returns a new Writer with empty reader and writer
+// portions, which are distinct maps sized for capacity elements.
+func NewMap[K comparable, V any](capacity int, opts ...Option[map[K]V]) *Writer[map[K]V] {
+	return New(make(map[K]V, capacity), make(map[K]V, capacity), opts...)
+}
+
+// CloneMap returns an independent copy of the published map of w.
+//
+// Calling CloneMap is threadsafe.
+func CloneMap[K comparable, V any](w *Writer[map[K]V]) map[K]V {
+	r := w.Reader()
+	defer r.Done()
+	v := r.Get()
+	m := make(map[K]V, len(v))
+	for k, e := range v {
+		m[k] = e
+	}
+	return m
+}
+
+// AggregateMap is like Aggregate for the published map of w.
+// The entries are visited in an unspecified order.
+//
+// Calling AggregateMap is threadsafe.
+func AggregateMap[K comparable, V, R any](w *Writer[map[K]V], fn func(acc R, key K, value V) R, init R) R {
+	r := w.Reader()
+	defer r.Done()
+	acc := init
+	for k, v := range r.Get() {
+		acc = fn(acc, k, v)
+	}
+	return acc
+}
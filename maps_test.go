@@ -0,0 +1,27 @@
+package readerwriter
+
+import "testing"
+
+func TestCloneMap(t *testing.T) {
+	w := NewMap[string, int](0)
+	w.Get()["foo"] = 1
+	w.Swap()
+	c := CloneMap(w)
+	c["foo"] = 2
+	if c := CloneMap(w); len(c) != 1 || c["foo"] != 1 {
+		t.Fatalf("got %v", c)
+	}
+}
+
+func TestAggregateMap(t *testing.T) {
+	w := New(map[string]int{"a": 1, "b": 2}, map[string]int{})
+	max := AggregateMap(w, func(acc string, k string, v int) string {
+		if acc == "" || v > 1 {
+			return k
+		}
+		return acc
+	}, "")
+	if max != "b" {
+		t.Fatalf("got %q, want %q", max, "b")
+	}
+}
@@ -0,0 +1,208 @@
+package readerwriter
+
+import (
+	"errors"
+	"time"
+)
+
+// Option configures a Writer, see New and Writer.Reconfigure.
+type Option[T any] struct {
+	apply func(w *Writer[T])
+	// reconfigurable options only change state that is owned
+	// by the writer goroutine, so they can be applied at runtime.
+	reconfigurable bool
+}
+
+func newOption[T any](apply func(w *Writer[T])) Option[T] {
+	return Option[T]{apply: apply}
+}
+
+func runtimeOption[T any](apply func(w *Writer[T])) Option[T] {
+	return Option[T]{apply: apply, reconfigurable: true}
+}
+
+// ErrNotReconfigurable is returned by Writer.Reconfigure for
+// options that can only be passed to New.
+var ErrNotReconfigurable = errors.New("option cannot be changed at runtime")
+
+// Reconfigure applies opts to the Writer. Only options that are
+// used exclusively by the writer goroutine can be changed at runtime:
+// WithCopy, WithValidator, WithDedup, WithMinSwapInterval,
+// WithSwapCoalescing, WithGenerationTrace, WithPublishTransform and
+// WithAdaptiveSwap. If any other option is passed,
+// ErrNotReconfigurable is returned and no option is applied.
+//
+// The new options take effect on the next writer method.
+func (w *Writer[T]) Reconfigure(opts ...Option[T]) error {
+	w.lockWriter()
+	defer w.unlockWriter()
+	for _, opt := range opts {
+		if !opt.reconfigurable {
+			return ErrNotReconfigurable
+		}
+	}
+	for _, opt := range opts {
+		opt.apply(w)
+	}
+	return nil
+}
+
+// WithCopy registers a copy function like NewWithCopy.
+func WithCopy[T any](copy func(dst, src T)) Option[T] {
+	return runtimeOption(func(w *Writer[T]) {
+		w.copy = uncountedCopy(copy)
+	})
+}
+
+// WithName labels the Writer, e.g. to tell multiple Writer's
+// apart in the payloads of hooks like WithSwapDeadline.
+// The name does not affect the behavior. It defaults to "".
+func WithName[T any](name string) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.name = name
+	})
+}
+
+// WithNoWriterCheck disables the detection of multiple writers,
+// which saves an uncontended mutex operation per writer method.
+//
+// Only use this if the Writer is provably used by a single goroutine
+// at a time: concurrent writer methods are not detected anymore and
+// result in data races, i.e. undefined behavior. See BenchmarkSet
+// for the difference. WithWriterDebug has no effect then.
+func WithNoWriterCheck[T any]() Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.noWriterCheck = true
+	})
+}
+
+// WithMaxReadersWarn calls fn when the number of active Reader's
+// exceeds threshold, that is when the count goes from threshold
+// to threshold+1. The Reader's of all generations are counted, like
+// ActiveReaders. fn fires once per crossing and not for every Reader
+// above the line or after a swap; it fires again only after the
+// count fell back to threshold.
+//
+// fn is called by the goroutine acquiring the Reader, so it
+// must be threadsafe and should return quickly.
+// It is only advisory and never blocks the acquisition.
+func WithMaxReadersWarn[T any](threshold int64, fn func(count int64)) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.maxReadersWarn = threshold
+		w.maxReadersWarnFn = fn
+	})
+}
+
+// WithMinSwapInterval enforces a minimum time of d between the
+// publication of two generations. By default a swap invoked too
+// early sleeps until d has elapsed since the previous one.
+// With WithSwapCoalescing it does nothing instead, that is the
+// pending writes stay in the writer portion and are published
+// together with the following writes by the next swap after d.
+func WithMinSwapInterval[T any](d time.Duration) Option[T] {
+	return runtimeOption(func(w *Writer[T]) {
+		w.minSwapInterval = d
+	})
+}
+
+// WithSwapCoalescing changes the policy of WithMinSwapInterval
+// from waiting to skipping swaps that are invoked too early.
+// SwapAsync returns an already closed channel in that case.
+func WithSwapCoalescing[T any]() Option[T] {
+	return runtimeOption(func(w *Writer[T]) {
+		w.coalesceSwaps = true
+	})
+}
+
+// WithOnDone calls fn when a Reader is done, before its generation
+// is released. userData is the value set with Reader.SetUserData.
+//
+// fn is called by the goroutine calling Done, so it must be
+// threadsafe and should return quickly to not stall a Swap.
+func WithOnDone[T any](fn func(generation uint64, userData any)) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.onDone = fn
+	})
+}
+
+// WithOnRead calls fn with the generation of every newly
+// acquired Reader, e.g. to trace the read volume.
+//
+// fn is called by the goroutine acquiring the Reader, after the
+// generation is locked. It must be threadsafe and cheap,
+// because acquiring a Reader is on the hot path.
+func WithOnRead[T any](fn func(generation uint64)) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.onRead = fn
+	})
+}
+
+// WithOnSwap calls fn after every swap, once the old Reader's are done.
+//
+// fn usually runs on the writer goroutine. The swaps of
+// SwapAsync call fn from a separate goroutine instead.
+func WithOnSwap[T any](fn func(SwapInfo)) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.onSwap = fn
+	})
+}
+
+// WithOnReaderTimeout calls fn, if ReaderContext gives up, because
+// ctx is done while it has to retry because of swaps. generationChurn
+// is the number of generations published while retrying, e.g. to
+// alert on a writer swapping so often that it starves Reader's.
+//
+// fn is called by the goroutine calling ReaderContext,
+// only on the timeout path.
+func WithOnReaderTimeout[T any](fn func(generationChurn int)) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.onReaderTimeout = fn
+	})
+}
+
+// WithReadCopy makes Reader.Get and Reader.Ptr return clone(v)
+// instead of the published value v itself, so Reader's handed to
+// less trusted code cannot corrupt the shared value. clone must
+// return a deep copy.
+//
+// This allocates on every Get, depending on clone. The returned
+// copy stays valid after Done, but it is not updated by later
+// swaps.
+func WithReadCopy[T any](clone func(T) T) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.readCopy = clone
+	})
+}
+
+// WithPublishTransform publishes transform(writer) instead of the
+// writer portion itself, e.g. a sorted copy of an unsorted slice.
+// transform runs on the writer goroutine before every publication.
+//
+// The result of transform must not share memory with the writer
+// portion. Because the writer portion is never published, it is
+// kept as is after a swap; the copy function (see NewWithCopy) is
+// not called and the old published value is left to the garbage
+// collector, once its Reader's are done.
+func WithPublishTransform[T any](transform func(writer T) T) Option[T] {
+	return runtimeOption(func(w *Writer[T]) {
+		w.publishTransform = transform
+	})
+}
+
+// WithValidator calls validate with the writer portion before
+// every swap. The swap is skipped if validate returns an error,
+// which SwapE returns alongside SkippedValidation.
+func WithValidator[T any](validate func(T) error) Option[T] {
+	return runtimeOption(func(w *Writer[T]) {
+		w.validate = validate
+	})
+}
+
+// WithDedup skips swaps for which equal(reader, writer) reports
+// that the writer portion does not differ from the published value.
+// SwapE returns SkippedDedup in that case.
+func WithDedup[T any](equal func(a, b T) bool) Option[T] {
+	return runtimeOption(func(w *Writer[T]) {
+		w.dedup = equal
+	})
+}
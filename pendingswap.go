@@ -0,0 +1,81 @@
+package readerwriter
+
+import "errors"
+
+const messagePendingSwapFinished = "pending swap already committed or rolled back"
+
+// ErrSwapObserved is returned by PendingSwap.Rollback if a Reader
+// already acquired the new generation.
+var ErrSwapObserved = errors.New("new generation already observed by a reader")
+
+// PendingSwap is a swap that is published, but not finished yet.
+// See Writer.BeginSwap.
+type PendingSwap[T any] struct {
+	w         *Writer[T]
+	newReader *current[T]
+	oldReader *current[T]
+	finished  bool
+}
+
+// BeginSwap publishes the writer portion like Swap, so new Reader's
+// see it immediately, but keeps the previous generation around.
+// The swap must be finished with exactly one successful call to
+// PendingSwap.Commit or PendingSwap.Rollback. Until then all other
+// writer methods panic, because the writer is still in use.
+//
+// Validators, dedup, intervals and suspension are not consulted,
+// deciding about the swap is up to the caller.
+func (w *Writer[T]) BeginSwap() *PendingSwap[T] {
+	w.lockWriter()
+	defer w.unlockWriterOnPanic()
+	newReader := w.nextGeneration(w.publishedValue())
+	oldReader := w.swapCurrent(newReader)
+	return &PendingSwap[T]{w: w, newReader: newReader, oldReader: oldReader}
+}
+
+// Commit finishes the swap like Swap, that is it waits for all
+// Reader's of the previous generation and copies with the
+// registered copy function.
+func (p *PendingSwap[T]) Commit() {
+	if p.finished {
+		panic(messagePendingSwapFinished)
+	}
+	p.finished = true
+	defer p.w.unlockWriter()
+	p.w.countSwap()
+	p.w.checkDeadlock(p.oldReader)
+	p.w.reclaim(p.newReader, p.oldReader, true)
+}
+
+// Rollback publishes the previous generation again and leaves the
+// writer portion untouched. This is only possible as long as no Reader
+// acquired the new generation, otherwise ErrSwapObserved is returned
+// and the swap is still pending.
+//
+// Because the previous generation keeps its number, CurrentGeneration
+// goes back by one. The rolled back generation is never reused, the
+// next swap publishes the one after it.
+func (p *PendingSwap[T]) Rollback() error {
+	if p.finished {
+		panic(messagePendingSwapFinished)
+	}
+	defer p.w.unlockWriterOnPanic()
+	// locking the new generation excludes active Reader's and
+	// makes new ones retry, until the previous one is back.
+	if !p.newReader.TryLock() {
+		return ErrSwapObserved
+	}
+	if p.newReader.observed.Load() {
+		p.newReader.Unlock()
+		return ErrSwapObserved
+	}
+	p.w.current.Store(p.oldReader)
+	p.newReader.Unlock()
+	p.finished = true
+	p.w.removeDraining(p.oldReader)
+	p.w.trace(GenerationRetired, p.newReader)
+	p.w.trace(GenerationPublished, p.oldReader)
+	p.w.didPublish(p.newReader.v, p.oldReader.v)
+	p.w.releaseWriterCheck()
+	return nil
+}
@@ -0,0 +1,71 @@
+package readerwriter
+
+import "testing"
+
+func TestPendingSwapRollback(t *testing.T) {
+	w := New(1, 2)
+	p := w.BeginSwap()
+	if err := p.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	r := w.Reader()
+	if r.Get() != 1 || r.Generation() != 0 {
+		t.Fatalf("got %d with generation %d", r.Get(), r.Generation())
+	}
+	r.Done()
+	if w.Get() != 2 {
+		t.Fatal("writer portion changed")
+	}
+}
+
+func TestPendingSwapCommit(t *testing.T) {
+	w := New(1, 2)
+	p := w.BeginSwap()
+	r := w.Reader()
+	if r.Get() != 2 {
+		t.Fatalf("got %d, want 2", r.Get())
+	}
+	r.Done()
+	if err := p.Rollback(); err != ErrSwapObserved {
+		t.Fatalf("got %v, want %v", err, ErrSwapObserved)
+	}
+	p.Commit()
+	if w.Get() != 1 || w.Stats().Swaps != 1 {
+		t.Fatal("swap not finished")
+	}
+}
+
+func TestPendingSwapRollbackGeneration(t *testing.T) {
+	var swaps []SwapInfo
+	w := New(0, 0, WithOnSwap[int](func(info SwapInfo) { swaps = append(swaps, info) }))
+	w.Set(1)
+	if err := w.BeginSwap().Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.CurrentGeneration(); got != 0 {
+		t.Fatalf("got generation %d after rollback, want 0", got)
+	}
+	w.Swap()
+	if got := w.CurrentGeneration(); got != 2 {
+		t.Fatalf("got generation %d, want 2, 1 was rolled back", got)
+	}
+	if len(swaps) != 1 || swaps[0].Generation != 2 {
+		t.Fatalf("unexpected swaps %+v", swaps)
+	}
+}
+
+func TestPendingSwapRollbackAdaptive(t *testing.T) {
+	w := New(0, 0, WithAdaptiveSwap[int](DefaultAdaptivePolicy(3)))
+	w.Set(1)
+	w.Set(2)
+	if err := w.BeginSwap().Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.Stats().WritesPerSwap.Count(); got != 0 {
+		t.Fatalf("got %d swaps with writes, want 0 after rollback", got)
+	}
+	w.Set(3)
+	if got := w.CurrentGeneration(); got != 2 {
+		t.Fatalf("got generation %d, want 2 after 3 pending writes", got)
+	}
+}
@@ -0,0 +1,16 @@
+package readerwriter
+
+// ReadProject returns project(v) for the published value v, e.g. a
+// single field of a large struct. The Reader is only held while
+// project is running, so project should be cheap and its result
+// must not share memory with v.
+//
+// ReadProject is a function instead of a method,
+// because methods cannot have type parameters.
+//
+// Calling ReadProject is threadsafe.
+func ReadProject[T, R any](w *Writer[T], project func(T) R) R {
+	r := w.Reader()
+	defer r.Done()
+	return project(r.Get())
+}
@@ -0,0 +1,14 @@
+package readerwriter
+
+import "testing"
+
+func TestReadProject(t *testing.T) {
+	type config struct {
+		name  string
+		large [1 << 10]int
+	}
+	w := New(config{name: "a"}, config{name: "b"})
+	if got := ReadProject(w, func(c config) string { return c.name }); got != "a" {
+		t.Fatalf("got %q, want %q", got, "a")
+	}
+}
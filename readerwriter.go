@@ -15,18 +15,42 @@
 package readerwriter
 
 import (
+	"context"
+	"runtime"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 const (
 	messageUsageOldReaderDetected  = "usage of an old reader detected"
 	messageMultipleWritersDetected = "multiple writers detected"
+	messageSwapWouldDeadlock       = "swap would deadlock on reader held by same goroutine"
 )
 
 type current[T any] struct {
 	sync.RWMutex
 	v T
+	// generation is atomic, because TryFastPublish
+	// advances it in place, see CurrentGeneration.
+	generation atomic.Uint64
+	buffer     uint64
+	// publishedAt is the time of the publication, see Reader.Age.
+	publishedAt time.Time
+
+	// readers counts the active Reader's of this generation.
+	readers atomic.Int64
+	// observed is set once a Reader acquired this generation.
+	observed atomic.Bool
+	// blockedSince is the time in unix nanoseconds since when
+	// draining this generation is blocked by its Reader's, or zero.
+	blockedSince atomic.Int64
+	// retired are the objects to reclaim after draining,
+	// see Writer.Retire.
+	retired []any
+	// replacedBy is the generation published in place
+	// of this one, which is reported after draining.
+	replacedBy Generation
 }
 
 // Writer represents the core abstraction of this package.
@@ -34,49 +58,318 @@ type current[T any] struct {
 // In general all methods are not threadsafe unless specified
 // otherwise.
 type Writer[T any] struct {
+	name    string
 	current atomic.Pointer[current[T]]
+	// generations is the greatest generation created so far. It can
+	// be greater than the published one after PendingSwap.Rollback.
+	generations Generation
+
+	drainingMu sync.Mutex
+	// draining contains the generations still waiting for their Reader's.
+	draining []*current[T]
 
 	unsyncWriterCheck sync.Mutex
+	noWriterCheck     bool
+	writerDebug       bool
+	writerStack       atomic.Pointer[[]byte]
+	writerPanicked    atomic.Bool
 	writerValue       T
+	writerBuffer      uint64
+	copy              func(dst, src T) (copied int)
+	asyncCopyBack     bool
+	copyBackDone      chan struct{}
+	// copyBackReader is the source of the pending copy,
+	// for the divergence check in waitCopyBack.
+	copyBackReader T
+
+	maxReadersWarn   int64
+	maxReadersWarnFn func(count int64)
+	// warnReaders counts the active Reader's of all generations
+	// for WithMaxReadersWarn, warnDisarmed is set after fn fired
+	// until the count is back at the threshold.
+	warnReaders     atomic.Int64
+	warnDisarmed    atomic.Bool
+	onDone          func(generation uint64, userData any)
+	onRead          func(generation uint64)
+	onSwap          func(SwapInfo)
+	onReaderTimeout func(generationChurn int)
+	readCopy        func(T) T
+	readSampler     *sampler
+	swapSampler     *sampler
+	traceSampler    *sampler
+
+	publishTransform func(writer T) T
+
+	validate func(T) error
+	dedup    func(a, b T) bool
+
+	suspended     bool
+	swapRequested bool
+
+	minSwapInterval time.Duration
+	coalesceSwaps   bool
+	lastSwap        time.Time
+
+	swapDeadline   time.Duration
+	swapDeadlineFn func(SwapStall)
+	trackReaders   bool
+	trackedMu      sync.Mutex
+	tracked        map[uint64]ReaderInfo
+	nextTrackID    uint64
+	leakFn         func(ReaderInfo)
+	autoDoneOnGC   bool
+
+	trackGoroutines bool
+	reentrancyCheck bool
+	deadlockCheck   bool
+	holdersMu       sync.Mutex
+	holders         map[uint64][]*current[T]
+
+	divergenceEqual func(a, b T) bool
+	divergenceFn    func(reader, writer T)
+	readerSinceSwap atomic.Bool
+	copiedLastSwap  bool
+
+	traceFn func(GenerationEvent)
+
+	readerPool sync.Pool
+
+	readerSlots       chan struct{}
+	blockOnMaxReaders bool
+	notAdmitting      atomic.Bool
+	closed            atomic.Bool
+	admittingMu       sync.Mutex
+	resumeAdmitting   chan struct{}
+
+	lastSwapBlocked atomic.Bool
+	lastSwapWait    atomic.Int64
+
+	adaptivePolicy AdaptivePolicy
+	pendingWrites  int
+
+	publishedMu sync.Mutex
+	published   sync.Cond
+	// publications counts the broadcasts of published, see waitPublished.
+	publications uint64
+	// subscribers report false once canceled, see SubscribeDiff.
+	subscribers []func(old, new T) bool
+
+	retired   []any
+	onReclaim func(obj any)
+
+	history history[T]
+
+	clock    TimeSource
+	backoff  BackoffStrategy
+	stats    stats
+	swapRing swapRing
 }
 
 // New returns a new Writer with the specified
 // reader and writer parts.
-func New[T any](reader, writer T) *Writer[T] {
+func New[T any](reader, writer T, opts ...Option[T]) *Writer[T] {
 	w := &Writer[T]{
-		writerValue: writer,
+		writerValue:  writer,
+		writerBuffer: 1,
+		clock:        realClock{},
+		backoff:      DefaultBackoff,
+	}
+	w.published.L = &w.publishedMu
+	for _, opt := range opts {
+		opt.apply(w)
 	}
-	w.current.Store(&current[T]{v: reader})
+	w.current.Store(&current[T]{v: reader, publishedAt: w.clock.Now()})
+	w.addHistory(reader)
 	return w
 }
 
+// NewWithCopy is like New, but Swap automatically copies the
+// new reader portion to the new writer portion by calling
+// copy(writer, reader) after all old Reader's are done.
+func NewWithCopy[T any](reader, writer T, copy func(dst, src T), opts ...Option[T]) *Writer[T] {
+	return NewWithCopyN(reader, writer, uncountedCopy(copy), opts...)
+}
+
+// NewWithCopyN is like NewWithCopy, but copy reports the number
+// of bytes it copied, which is accumulated in Stats.TotalBytesCopied.
+func NewWithCopyN[T any](reader, writer T, copy func(dst, src T) (copied int), opts ...Option[T]) *Writer[T] {
+	w := New(reader, writer, opts...)
+	w.copy = copy
+	return w
+}
+
+// SetCopyFunc replaces the copy function registered with
+// NewWithCopy. A nil copy disables the automatic copy.
+// The change takes effect on the next Swap.
+func (w *Writer[T]) SetCopyFunc(copy func(dst, src T)) {
+	w.SetCopyFuncN(uncountedCopy(copy))
+}
+
+// SetCopyFuncN is like SetCopyFunc for copy functions
+// of NewWithCopyN.
+func (w *Writer[T]) SetCopyFuncN(copy func(dst, src T) (copied int)) {
+	w.lockWriter()
+	defer w.unlockWriter()
+	w.copy = copy
+}
+
+func uncountedCopy[T any](copy func(dst, src T)) func(dst, src T) int {
+	if copy == nil {
+		return nil
+	}
+	return func(dst, src T) int {
+		copy(dst, src)
+		return 0
+	}
+}
+
+// Name returns the name set with WithName.
+//
+// Calling Name is threadsafe.
+func (w *Writer[T]) Name() string {
+	return w.name
+}
+
+func (w *Writer[T]) lockWriter() {
+	if !w.noWriterCheck {
+		if !w.unsyncWriterCheck.TryLock() {
+			w.multipleWritersDetected()
+		}
+		if w.writerDebug {
+			w.recordWriter()
+		}
+	}
+	if w.asyncCopyBack {
+		w.waitCopyBack()
+	}
+}
+
+// unlockWriter must be deferred by the writer methods, so
+// a panic, e.g. in a callback, is recorded for WriterPanicked
+// and does not leave the writer check locked.
+func (w *Writer[T]) unlockWriter() {
+	p := recover()
+	if p != nil {
+		w.writerPanicked.Store(true)
+	}
+	w.releaseWriterCheck()
+	if p != nil {
+		panic(p)
+	}
+}
+
+// unlockWriterOnPanic is deferred instead of unlockWriter by the
+// writer methods, which keep the writer check locked on success,
+// like BeginSwap.
+func (w *Writer[T]) unlockWriterOnPanic() {
+	if p := recover(); p != nil {
+		w.writerPanicked.Store(true)
+		w.releaseWriterCheck()
+		panic(p)
+	}
+}
+
+func (w *Writer[T]) releaseWriterCheck() {
+	if !w.noWriterCheck {
+		if w.writerDebug {
+			w.writerStack.Store(nil)
+		}
+		w.unsyncWriterCheck.Unlock()
+	}
+}
+
+// WriterPanicked reports whether a writer method panicked before,
+// e.g. because of a panicking callback in the middle of a swap.
+// The Writer might be in an inconsistent state afterwards, so it
+// should be replaced, even though the writer methods can still
+// be called.
+//
+// Calling WriterPanicked is threadsafe.
+func (w *Writer[T]) WriterPanicked() bool {
+	return w.writerPanicked.Load()
+}
+
 // Get returns the current writer portion. The returned value
 // should only be used until calling Swap.
 func (w *Writer[T]) Get() T {
+	w.lockWriter()
+	defer w.unlockWriter()
+	return w.writerValue
+}
+
+// TryGetWriter is like Get, but reports false instead of
+// panicking, if another goroutine is inside a writer method.
+// The returned value should only be used until calling Swap and
+// must not be modified, unless the caller is the writer goroutine.
+// With WithNoWriterCheck the writer can't be excluded, so
+// TryGetWriter always reports false.
+//
+// Calling TryGetWriter is threadsafe.
+func (w *Writer[T]) TryGetWriter() (writer T, ok bool) {
+	if w.noWriterCheck {
+		return writer, false
+	}
 	if !w.unsyncWriterCheck.TryLock() {
-		panic(messageMultipleWritersDetected)
+		return writer, false
 	}
 	defer w.unsyncWriterCheck.Unlock()
-	return w.writerValue
+	if w.copyBackPending() {
+		return writer, false
+	}
+	return w.writerValue, true
 }
 
 // Set sets the current writer portion.
 func (w *Writer[T]) Set(v T) (previous T) {
-	if !w.unsyncWriterCheck.TryLock() {
-		panic(messageMultipleWritersDetected)
-	}
-	defer w.unsyncWriterCheck.Unlock()
+	w.lockWriter()
+	defer w.unlockWriter()
 	previous = w.writerValue
 	w.writerValue = v
+	w.wrote()
 	return previous
 }
 
+// SetIfChanged sets the current writer portion to v, unless
+// equal(current, v) is true. It reports whether v was set.
+func (w *Writer[T]) SetIfChanged(v T, equal func(a, b T) bool) bool {
+	w.lockWriter()
+	defer w.unlockWriter()
+	if equal(w.writerValue, v) {
+		return false
+	}
+	w.writerValue = v
+	w.wrote()
+	return true
+}
+
+// Verify calls check with the published value and the writer
+// portion and returns its error, e.g. to assert invariants of
+// both portions in tests. No swap can happen during check,
+// because Verify is a writer method. check must not modify
+// the published value.
+func (w *Writer[T]) Verify(check func(reader, writer T) error) error {
+	w.lockWriter()
+	defer w.unlockWriter()
+	r := w.Reader()
+	defer r.Done()
+	return check(r.Get(), w.writerValue)
+}
+
 // Reader represents the reader portion. A Reader is
 // not threadsafe.
 type Reader[T any] struct {
-	mu   *sync.RWMutex
-	done bool
-	v    T
+	w       *Writer[T]
+	current *current[T]
+	// done is atomic, because ReaderWithTTL
+	// might release the Reader from a timer.
+	done     atomic.Bool
+	userData any
+	trackID  uint64
+	// goroutine acquired the Reader, see WithReentrancyCheck.
+	goroutine uint64
+	pooled    bool
+	// ttl is set by ReaderWithTTL.
+	ttl *readerTTL
 }
 
 // Reader returns the current reader portion. This operation
@@ -88,22 +381,269 @@ type Reader[T any] struct {
 //
 // Calling Reader is threadsafe.
 func (w *Writer[T]) Reader() *Reader[T] {
-	for {
-		current := w.current.Load()
-		if !current.TryRLock() {
-			// the writer is waiting for the readers to perform the swap,
-			// which means we should load again.
-			continue
+	w.admit()
+	return w.newReader(w.acquire())
+}
+
+// acquire locks the current generation for a new Reader.
+func (w *Writer[T]) acquire() *current[T] {
+	if c := w.tryAcquire(); c != nil {
+		return c
+	}
+	start := w.clock.Now()
+	for attempt := 0; ; attempt++ {
+		if w.notAdmitting.Load() {
+			if w.closed.Load() {
+				w.release()
+				panic(messageClosed)
+			}
+			w.waitAdmitting()
+		}
+		w.backoff.Backoff(attempt, w.clock)
+		if c := w.tryAcquire(); c != nil {
+			w.stats.acquireLatency.observe(int64(w.since(start)))
+			return c
+		}
+	}
+}
+
+// ReaderContext is like Reader, but gives up once ctx is done.
+// The returned error is ctx.Err() in that case.
+//
+// Calling ReaderContext is threadsafe.
+func (w *Writer[T]) ReaderContext(ctx context.Context) (*Reader[T], error) {
+	if err := w.admitContext(ctx); err != nil {
+		return nil, err
+	}
+	var startGeneration Generation
+	if w.onReaderTimeout != nil {
+		startGeneration = w.CurrentGeneration()
+	}
+	if c := w.tryAcquire(); c != nil {
+		return w.newReader(c), nil
+	}
+	start := w.clock.Now()
+	for attempt := 0; ; attempt++ {
+		if err := ctx.Err(); err != nil {
+			w.release()
+			if w.onReaderTimeout != nil {
+				w.onReaderTimeout(int(w.CurrentGeneration() - startGeneration))
+			}
+			return nil, err
+		}
+		if w.notAdmitting.Load() {
+			w.release()
+			if w.closed.Load() {
+				return nil, ErrClosed
+			}
+			return nil, ErrNotAdmitting
+		}
+		w.backoff.Backoff(attempt, w.clock)
+		if c := w.tryAcquire(); c != nil {
+			w.stats.acquireLatency.observe(int64(w.since(start)))
+			return w.newReader(c), nil
 		}
-		afterRLock := w.current.Load()
-		if current != afterRLock {
-			// in case the writer swaps and unlocks
-			// between our load and lock attempt.
-			current.RUnlock()
-			continue
+	}
+}
+
+// ReadOrStale returns the current reader value, or cached if
+// no Reader could be acquired within timeout.
+//
+// Acquiring a Reader only has to be retried while a Swap is
+// in progress, so cached is returned only if the writer keeps
+// swapping for longer than timeout. A timeout <= 0 means a
+// single attempt. The first attempt never reads the clock.
+//
+// The Reader is released before returning, so the result is only
+// safe to use if T does not share memory with the reader portion
+// (e.g. plain values or immutable data).
+//
+// Calling ReadOrStale is threadsafe.
+func (w *Writer[T]) ReadOrStale(timeout time.Duration, cached T) T {
+	var deadline time.Time
+	for attempt := 0; ; attempt++ {
+		if r, ok := w.tryReader(); ok {
+			v := r.Get()
+			r.Done()
+			return v
+		}
+		if deadline.IsZero() {
+			if timeout <= 0 {
+				return cached
+			}
+			deadline = w.clock.Now().Add(timeout)
+		} else if !w.clock.Now().Before(deadline) {
+			return cached
+		}
+		w.backoff.Backoff(attempt, w.clock)
+	}
+}
+
+// ReaderForRequest is like Reader, but the Reader is taken from
+// a pool and returned to it by Done, which avoids an allocation
+// per request in servers, e.g. in a middleware:
+//
+//	r := w.ReaderForRequest()
+//	defer r.Done()
+//
+// The Reader keeps its generation until Done, even if a Swap
+// happens in the meantime. Because of the reuse, using the Reader
+// after Done is not always detected and leads to reading a random
+// generation. Pooled Reader's are not covered by WithLeakDetection.
+//
+// Calling ReaderForRequest is threadsafe.
+func (w *Writer[T]) ReaderForRequest() *Reader[T] {
+	r, _ := w.readerPool.Get().(*Reader[T])
+	if r == nil {
+		r = &Reader[T]{w: w, pooled: true}
+	}
+	w.admit()
+	r.current = w.acquire()
+	r.done.Store(false)
+	w.attach(r)
+	return r
+}
+
+// TryReader makes a single attempt to acquire a Reader without
+// spinning. It returns nil, false if a Swap is in progress,
+// then the caller decides whether to retry later.
+//
+// Calling TryReader is threadsafe.
+func (w *Writer[T]) TryReader() (*Reader[T], bool) {
+	return w.tryReader()
+}
+
+// ReadWithGeneration returns the published value and its generation
+// (see Reader.Generation) together with a function, which releases
+// the underlying Reader like Reader.Done. Calling release more than
+// once has no effect. The value must not be used after release.
+//
+// Calling ReadWithGeneration is threadsafe.
+func (w *Writer[T]) ReadWithGeneration() (v T, generation Generation, release func()) {
+	r := w.Reader()
+	released := false
+	release = func() {
+		if !released {
+			released = true
+			r.Done()
 		}
-		return &Reader[T]{mu: &current.RWMutex, v: current.v}
 	}
+	return r.Get(), r.Generation(), release
+}
+
+// tryReader makes a single attempt to acquire a Reader.
+func (w *Writer[T]) tryReader() (*Reader[T], bool) {
+	if !w.tryAdmit() {
+		return nil, false
+	}
+	c := w.tryAcquire()
+	if c == nil {
+		w.release()
+		return nil, false
+	}
+	return w.newReader(c), true
+}
+
+// tryAcquire makes a single attempt to lock the current
+// generation for a new Reader. It returns nil on failure.
+func (w *Writer[T]) tryAcquire() *current[T] {
+	current := w.current.Load()
+	testHook(hookReaderLoaded)
+	if !current.TryRLock() {
+		// the writer is waiting for the readers to perform the swap,
+		// which means we should load again.
+		return nil
+	}
+	afterRLock := w.current.Load()
+	if current != afterRLock {
+		// in case the writer swaps and unlocks
+		// between our load and lock attempt.
+		current.RUnlock()
+		return nil
+	}
+	current.readers.Add(1)
+	if w.notAdmitting.Load() {
+		// checked after counting the reader, so StopAdmitting
+		// either sees the reader or the reader sees StopAdmitting.
+		current.readers.Add(-1)
+		current.RUnlock()
+		return nil
+	}
+	if w.maxReadersWarnFn != nil {
+		w.warnAcquired()
+	}
+	if !current.observed.Load() {
+		current.observed.Store(true)
+	}
+	if w.divergenceFn != nil {
+		w.readerSinceSwap.Store(true)
+	}
+	if w.onRead != nil && w.readSampler.sample() {
+		w.onRead(current.generation.Load())
+	}
+	return current
+}
+
+// warnAcquired calls the WithMaxReadersWarn hook, if the
+// active Reader's exceed the threshold and it is armed.
+func (w *Writer[T]) warnAcquired() {
+	n := w.warnReaders.Add(1)
+	if n > w.maxReadersWarn && w.warnDisarmed.CompareAndSwap(false, true) {
+		w.maxReadersWarnFn(n)
+	}
+}
+
+// warnReleased arms the WithMaxReadersWarn hook again,
+// once the active Reader's are back at the threshold.
+func (w *Writer[T]) warnReleased() {
+	if w.warnReaders.Add(-1) <= w.maxReadersWarn {
+		w.warnDisarmed.Store(false)
+	}
+}
+
+// newReader returns a Reader for the acquired generation c.
+func (w *Writer[T]) newReader(c *current[T]) *Reader[T] {
+	r := &Reader[T]{w: w, current: c}
+	w.attach(r)
+	if w.leakFn != nil || w.autoDoneOnGC {
+		w.watchLeak(r)
+	}
+	return r
+}
+
+func (w *Writer[T]) attach(r *Reader[T]) {
+	if w.trackReaders {
+		w.track(r)
+	}
+	if w.trackGoroutines {
+		w.enterRead(r)
+	}
+}
+
+// ActiveReaders returns the number of Reader's that are not done yet,
+// including the ones still holding up a Swap.
+// The result is only a snapshot and might be stale immediately.
+//
+// Calling ActiveReaders is threadsafe.
+func (w *Writer[T]) ActiveReaders() int64 {
+	n := w.current.Load().readers.Load()
+	w.drainingMu.Lock()
+	defer w.drainingMu.Unlock()
+	for _, c := range w.draining {
+		n += c.readers.Load()
+	}
+	return n
+}
+
+// HasReaders reports whether the published generation has active
+// Reader's, with a single atomic load. In contrast to ActiveReaders
+// it ignores the generations still held up by a Swap. The result is
+// only advisory and might be stale immediately, e.g. to decide
+// whether TryFastPublish is worth a try.
+//
+// Calling HasReaders is threadsafe.
+func (w *Writer[T]) HasReaders() bool {
+	return w.current.Load().readers.Load() != 0
 }
 
 // Get returns the value of the current Reader.
@@ -111,20 +651,191 @@ func (w *Writer[T]) Reader() *Reader[T] {
 // Usually the caller should not modify the
 // returned value or use it after calling Done.
 func (r *Reader[T]) Get() T {
-	if r.done {
+	if r.done.Load() {
 		panic(messageUsageOldReaderDetected)
 	}
-	return r.v
+	if clone := r.w.readCopy; clone != nil {
+		return clone(r.current.v)
+	}
+	return r.current.v
+}
+
+// Ptr is like Get, but returns a pointer to the value of the
+// Reader instead of a copy, e.g. to read single fields of a large
+// struct cheaply.
+//
+// The pointed to value must be treated as read-only and the pointer
+// must not be used after calling Done, because the Writer reuses
+// the value afterwards.
+func (r *Reader[T]) Ptr() *T {
+	if r.done.Load() {
+		panic(messageUsageOldReaderDetected)
+	}
+	if clone := r.w.readCopy; clone != nil {
+		v := clone(r.current.v)
+		return &v
+	}
+	return &r.current.v
+}
+
+// Generation identifies a published value of a Writer.
+// Generations are monotonic, a later Swap always publishes
+// a greater Generation and a Generation is never reused for
+// another value. The only exception is PendingSwap.Rollback,
+// which publishes the previous, smaller Generation again.
+type Generation = uint64
+
+// Before reports whether a was published before b.
+func Before(a, b Generation) bool {
+	return a < b
+}
+
+// Generation returns the generation of the value of the Reader.
+// The initial reader portion has generation 0, every Swap
+// publishes the next generation.
+func (r *Reader[T]) Generation() Generation {
+	if r.done.Load() {
+		panic(messageUsageOldReaderDetected)
+	}
+	return r.current.generation.Load()
+}
+
+// Age returns how long ago the value of the Reader was published,
+// e.g. to measure the staleness of a long-held Reader.
+func (r *Reader[T]) Age() time.Duration {
+	if r.done.Load() {
+		panic(messageUsageOldReaderDetected)
+	}
+	return r.w.since(r.current.publishedAt)
+}
+
+// CurrentGeneration returns the generation of the published value
+// with two atomic loads, without acquiring a Reader. A Reader acquired
+// right afterwards has at least this generation, unless a
+// PendingSwap is rolled back in between.
+//
+// Calling CurrentGeneration is threadsafe.
+func (w *Writer[T]) CurrentGeneration() Generation {
+	return w.current.Load().generation.Load()
+}
+
+// GenerationBefore reports whether the value of r was published
+// before the value of other. Both Reader's must belong to the same
+// Writer and must not be done.
+func (r *Reader[T]) GenerationBefore(other *Reader[T]) bool {
+	return Before(r.Generation(), other.Generation())
 }
 
 // Done must be called when finished reading,
 // so the Writer can make progress.
 func (r *Reader[T]) Done() {
-	if r.done {
+	if t := r.ttl; t != nil {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.stop()
+		if t.expired {
+			// released by the TTL, see ReaderWithTTL.
+			return
+		}
+	}
+	if !r.finish() {
 		panic(messageUsageOldReaderDetected)
 	}
-	r.done = true
-	r.mu.RUnlock()
+}
+
+// finish releases the generation of the Reader. It reports false,
+// if the Reader was done already, then nothing is released.
+func (r *Reader[T]) finish() bool {
+	if !r.done.CompareAndSwap(false, true) {
+		return false
+	}
+	if onDone := r.w.onDone; onDone != nil {
+		onDone(r.current.generation.Load(), r.userData)
+	}
+	if r.w.trackReaders {
+		r.w.untrack(r)
+	}
+	if r.w.trackGoroutines {
+		r.w.exitRead(r)
+	}
+	w, c := r.w, r.current
+	if r.pooled {
+		*r = Reader[T]{w: w, pooled: true}
+		r.done.Store(true)
+		w.readerPool.Put(r)
+	}
+	c.readers.Add(-1)
+	c.RUnlock()
+	if w.maxReadersWarnFn != nil {
+		w.warnReleased()
+	}
+	w.release()
+	return true
+}
+
+// Renew keeps the Reader on its generation and reports true,
+// if the generation is still published. Otherwise a Swap is in
+// progress or finished: Renew releases the old generation, which
+// might let the Swap proceed, acquires the published one like
+// Reader and reports false, meaning Get can return a different
+// value now. Values obtained from the Reader before must not be
+// used after Renew reported false.
+//
+// Renew blocks like Reader, e.g. between StopAdmitting and
+// ResumeAdmitting. If it panics, the Reader is done. The timer of
+// ReaderWithTTL starts again, if the published generation is acquired.
+func (r *Reader[T]) Renew() bool {
+	t := r.ttl
+	if t != nil {
+		// excludes the release by the timer.
+		t.mu.Lock()
+		defer t.mu.Unlock()
+	}
+	if r.done.Load() {
+		panic(messageUsageOldReaderDetected)
+	}
+	w, old := r.w, r.current
+	if w.current.Load() == old {
+		return true
+	}
+	if t != nil {
+		t.stop()
+	}
+	if w.trackReaders {
+		w.untrack(r)
+	}
+	if w.trackGoroutines {
+		w.exitRead(r)
+	}
+	r.done.Store(true)
+	old.readers.Add(-1)
+	old.RUnlock()
+	if w.maxReadersWarnFn != nil {
+		w.warnReleased()
+	}
+	// the admission slot is kept, the Reader is still admitted.
+	r.current = w.acquire()
+	r.done.Store(false)
+	w.attach(r)
+	if t != nil {
+		r.startTTL()
+	}
+	return false
+}
+
+// SetUserData attaches arbitrary data to the Reader, e.g. a request ID.
+// The package ignores it except for passing it to the WithOnDone hook.
+func (r *Reader[T]) SetUserData(data any) {
+	if t := r.ttl; t != nil {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+	}
+	r.userData = data
+}
+
+// UserData returns the data set by SetUserData or nil.
+func (r *Reader[T]) UserData() any {
+	return r.userData
 }
 
 // Swap exchanges the reader and writer portion and waits for
@@ -132,19 +843,645 @@ func (r *Reader[T]) Done() {
 //
 // Usually the accumulated writes are copied by the caller
 // to the new writer portion after this method returns.
+// If a copy function is registered (see NewWithCopy),
+// Swap does this automatically.
+//
+// With WithMinSwapInterval, Swap might wait or do nothing,
+// see there. WithValidator and WithDedup can prevent it too.
+// Use SwapE to find out what happened.
 func (w *Writer[T]) Swap() {
-	if !w.unsyncWriterCheck.TryLock() {
-		panic(messageMultipleWritersDetected)
+	w.lockWriter()
+	defer w.unlockWriter()
+	w.swap(true)
+}
+
+// SwapE is like Swap, but reports whether the swap happened
+// or why it was skipped. The error is non-nil only if a callback
+// failed, e.g. a validator (see WithValidator) rejected the writer
+// portion. It is of type *SwapError then.
+func (w *Writer[T]) SwapE() (SwapResult, error) {
+	w.lockWriter()
+	defer w.unlockWriter()
+	return w.swap(true)
+}
+
+// SwapNoCopy is like Swap, but never calls the registered
+// copy function. Use it if the new writer portion is
+// replaced completely afterwards anyway, e.g. with Set,
+// to avoid a wasted copy.
+func (w *Writer[T]) SwapNoCopy() {
+	w.lockWriter()
+	defer w.unlockWriter()
+	w.swap(false)
+}
+
+// SwapIfGeneration is like Swap, but only swaps if the published
+// generation is still expected (see Reader.Generation) and
+// reports whether it did.
+//
+// The comparison and the publication of generation expected+1
+// happen atomically with respect to other writer methods.
+// Reader's acquired after the comparison see
+// either generation expected or expected+1.
+func (w *Writer[T]) SwapIfGeneration(expected Generation) bool {
+	w.lockWriter()
+	defer w.unlockWriter()
+	if w.current.Load().generation.Load() != expected {
+		return false
 	}
-	defer w.unsyncWriterCheck.Unlock()
+	result, _ := w.swap(true)
+	return result == Published
+}
+
+// PublishAndReset publishes the current writer portion like
+// SwapNoCopy and sets the new writer portion to reset(reclaimed)
+// once all old Reader's are done, which is also returned.
+// For a slice reset is usually func(s []E) []E { return s[:0] }.
+//
+// If the swap is skipped (see SwapE), the writer portion
+// is returned unchanged.
+func (w *Writer[T]) PublishAndReset(reset func(T) T) (reclaimed T) {
+	w.lockWriter()
+	defer w.unlockWriter()
+	if result, _ := w.swap(false); result == Published {
+		w.writerValue = reset(w.writerValue)
+	}
+	return w.writerValue
+}
+
+// SwapThen is like PublishAndReset, but does not return the new
+// writer portion: the copy function (see NewWithCopy) is skipped
+// and prepare rebuilds the reclaimed buffer on the writer goroutine
+// instead, e.g. by clearing and seeding it. prepare is not called,
+// if the swap is skipped.
+func (w *Writer[T]) SwapThen(prepare func(reclaimed T) T) {
+	w.PublishAndReset(prepare)
+}
+
+// SuspendSwaps prevents all swaps until ResumeSwaps is called,
+// e.g. to not publish partial state during a bulk load.
+// Swaps in the meantime do nothing and SwapE reports Suspended.
+func (w *Writer[T]) SuspendSwaps() {
+	w.lockWriter()
+	defer w.unlockWriter()
+	w.suspended = true
+}
+
+// ResumeSwaps allows swaps again. If swaps were attempted while
+// suspended, a single Swap publishes the accumulated state.
+func (w *Writer[T]) ResumeSwaps() {
+	w.lockWriter()
+	defer w.unlockWriter()
+	w.suspended = false
+	if w.swapRequested {
+		w.swapRequested = false
+		w.swap(true)
+	}
+}
+
+// TryFastPublish publishes v in place, if the published generation
+// has no active Reader's. This avoids the allocation and the drain
+// of Swap. It reports false if there might be Reader's, then the
+// caller should fall back to Swap.
+//
+// v is checked like the writer portion by Swap: TryFastPublish also
+// reports false, if swaps are suspended or v is skipped by the
+// validator, dedup or interval (see SwapE). A fallback to Swap
+// reports the reason in that case.
+//
+// The published generation is locked while updating, so Reader's
+// arriving concurrently cannot observe a partial update, they just
+// retry. The generation is advanced by one like with Swap.
+// The writer portion is not changed, so v must not share memory with it.
+// With WithPublishTransform the result of transform(v) is published.
+func (w *Writer[T]) TryFastPublish(v T) bool {
+	w.lockWriter()
+	defer w.unlockWriter()
+	if w.suspended {
+		return false
+	}
+	if result, _ := w.checkSwap(v); result != Published {
+		return false
+	}
+	return w.tryFastPublish(v)
+}
+
+func (w *Writer[T]) tryFastPublish(v T) bool {
+	c := w.current.Load()
+	if c.readers.Load() != 0 || !c.TryLock() {
+		return false
+	}
+	if w.publishTransform != nil {
+		v = w.publishTransform(v)
+	}
+	old := c.v
+	c.v = v
+	w.generations++
+	c.generation.Store(w.generations)
+	c.publishedAt = w.clock.Now()
+	c.Unlock()
+	w.trace(GenerationPublished, c)
+	w.countSwap()
+	w.didPublish(old, v)
+	return true
+}
+
+// SmartPublish publishes v in place like TryFastPublish, if the
+// published generation has no active Reader's, otherwise it falls
+// back to Set(v) followed by Swap. Whenever a Reader might arrive
+// during the decision, the swap is chosen, so SmartPublish is always
+// safe. v must not share memory with the writer portion.
+//
+// Both ways v is checked once like the writer portion by SwapE,
+// which SmartPublish reports like SwapE. If swaps are suspended,
+// v becomes the writer portion, which is published on ResumeSwaps.
+//
+// If v is published, the copy function (see NewWithCopy) is called
+// afterwards, so the writer portion equals v. Without a copy
+// function the writer portion is undefined. With WithPublishTransform
+// the result of transform(v) is published and v becomes the writer
+// portion.
+func (w *Writer[T]) SmartPublish(v T) (SwapResult, error) {
+	w.lockWriter()
+	defer w.unlockWriter()
+	result, err := w.checkSwap(v)
+	if result == Suspended {
+		w.writerValue = v
+	}
+	if result != Published {
+		return result, err
+	}
+	if !w.HasReaders() && w.tryFastPublish(v) {
+		if w.publishTransform != nil {
+			w.writerValue = v
+		} else if w.copy != nil {
+			w.copyBack(v)
+		}
+		return Published, nil
+	}
+	w.writerValue = v
+	w.swapChecked(true)
+	return Published, nil
+}
 
+// FlushForRead makes the current writer portion visible to
+// Reader's, e.g. to make assertions on it in tests.
+//
+// It is a full Swap under the hood, including the copy with the
+// registered copy function (see NewWithCopy), and therefore not cheap.
+// Without a copy function the new writer portion is the old reader
+// portion afterwards, like with Swap. If there are no concurrent
+// Reader's it never blocks.
+func (w *Writer[T]) FlushForRead() {
+	w.Swap()
+}
+
+// PublishBarrier makes the memory ordering of a Swap explicit for
+// data, which is reachable from the published value, but mutated
+// by the writer goroutine with its own synchronization.
+//
+// Writes of the calling goroutine before PublishBarrier happen before
+// everything a Reader does, which is acquired after PublishBarrier
+// returns: PublishBarrier stores the published pointer again with an
+// atomic compare-and-swap, which the Reader loads. A Swap already
+// gives the same guarantee for the writer portion it publishes, Set
+// alone gives none. Reader's acquired before PublishBarrier are not
+// ordered by it. PublishBarrier does not change the published value
+// or its generation.
+func (w *Writer[T]) PublishBarrier() {
+	w.lockWriter()
+	defer w.unlockWriter()
+	c := w.current.Load()
+	w.current.CompareAndSwap(c, c)
+}
+
+func (w *Writer[T]) swap(copyBack bool) (SwapResult, error) {
+	if result, err := w.checkSwap(w.writerValue); result != Published {
+		return result, err
+	}
+	w.swapChecked(copyBack)
+	return Published, nil
+}
+
+// swapChecked is swap after checkSwap allowed it.
+func (w *Writer[T]) swapChecked(copyBack bool) {
+	w.checkDeadlock(w.current.Load())
 	// new readers can use the new value immediately,
 	// but the next writer has to wait until everybody is done reading.
-	oldReader := w.current.Swap(&current[T]{v: w.writerValue})
-	oldReader.Lock()
-	_ = "noop" // silence static analysis
-	oldReader.Unlock()
+	newReader, oldReader := w.publish(w.publishedValue())
+	w.reclaim(newReader, oldReader, copyBack)
+}
+
+// publishedValue returns the value to publish for the writer portion.
+func (w *Writer[T]) publishedValue() T {
+	if w.publishTransform != nil {
+		return w.publishTransform(w.writerValue)
+	}
+	return w.writerValue
+}
+
+// reclaim drains old and makes its value the writer portion.
+// With WithPublishTransform the writer portion is kept instead.
+func (w *Writer[T]) reclaim(newReader, oldReader *current[T], copyBack bool) {
+	w.drain(oldReader)
+	if w.publishTransform != nil {
+		return
+	}
 	w.writerValue = oldReader.v
+	w.writerBuffer = oldReader.buffer
+	w.trace(GenerationReused, oldReader)
+	if copyBack && w.copy != nil {
+		w.copyBack(newReader.v)
+	} else if w.divergenceFn != nil {
+		w.checkDivergenceAfterSwap(newReader.v, false)
+	}
 
 	// do stuff after this ...
 }
+
+// checkSwap decides whether v, usually the writer
+// portion, should be published.
+func (w *Writer[T]) checkSwap(v T) (SwapResult, error) {
+	if w.suspended {
+		w.swapRequested = true
+		return Suspended, nil
+	}
+	if w.validate != nil {
+		if err := w.validate(v); err != nil {
+			return SkippedValidation, &SwapError{
+				Phase:      PhaseValidate,
+				Generation: w.generations + 1,
+				Err:        err,
+			}
+		}
+	}
+	if w.dedup != nil && w.dedup(w.current.Load().v, v) {
+		return SkippedDedup, nil
+	}
+	if !w.waitSwapInterval() {
+		return SkippedConditional, nil
+	}
+	return Published, nil
+}
+
+// SwapAsync is like SwapNoCopy, but does not wait for the old
+// Reader's. Instead the returned channel is closed once they are done,
+// so it can be used in a select statement. Multiple SwapAsync's can
+// be in progress at the same time, each with its own channel.
+//
+// The new writer portion is returned by Get immediately,
+// but it must not be modified until the corresponding channel
+// is closed, because old Reader's might still use it.
+func (w *Writer[T]) SwapAsync() <-chan struct{} {
+	w.lockWriter()
+	defer w.unlockWriter()
+
+	done := make(chan struct{})
+	if result, _ := w.checkSwap(w.writerValue); result != Published {
+		close(done)
+		return done
+	}
+	_, oldReader := w.publish(w.publishedValue())
+	if w.publishTransform == nil {
+		w.writerValue = oldReader.v
+		w.writerBuffer = oldReader.buffer
+		w.trace(GenerationReused, oldReader)
+	}
+
+	go func() {
+		w.drain(oldReader)
+		close(done)
+	}()
+	return done
+}
+
+// Warm exercises the read and the swap path once before real traffic,
+// to reduce the latency of the first calls: twice it acquires,
+// reads and releases a Reader with Reader and ReaderForRequest,
+// followed by a swap without copy and without consulting the
+// validators, dedup, intervals and suspension.
+//
+// Afterwards the portions are the same as before, but the
+// generation is advanced by two and the swaps are visible in
+// Stats and the callbacks. With WithPublishTransform the
+// transformed writer portion is published afterwards.
+// Warm must be called before any concurrent Reader's exist.
+func (w *Writer[T]) Warm() {
+	w.lockWriter()
+	defer w.unlockWriter()
+	for i := 0; i < 2; i++ {
+		r := w.Reader()
+		_ = r.Get()
+		r.Done()
+		r = w.ReaderForRequest()
+		_ = r.Get()
+		r.Done()
+
+		newReader, oldReader := w.publish(w.publishedValue())
+		w.reclaim(newReader, oldReader, false)
+	}
+}
+
+// SwapWithBuffer publishes the writer portion like SwapNoCopy, but
+// continues with newWriter as the writer portion instead of reusing
+// the buffer of the old generation. The old buffer is returned once
+// its Reader's are done, so it can be processed elsewhere without
+// aliasing the Writer. newWriter must not share memory with the
+// published value.
+//
+// If the swap is skipped (see SwapE), the writer portion
+// is unchanged and newWriter is returned.
+func (w *Writer[T]) SwapWithBuffer(newWriter T) (oldWriter T) {
+	w.lockWriter()
+	defer w.unlockWriter()
+	if result, _ := w.swap(false); result != Published {
+		return newWriter
+	}
+	oldWriter = w.writerValue
+	w.writerValue = newWriter
+	if buffer := w.current.Load().buffer; buffer > w.writerBuffer {
+		w.writerBuffer = buffer + 1
+	} else {
+		w.writerBuffer++
+	}
+	// the writer portion was provided by the caller
+	w.copiedLastSwap = true
+	return oldWriter
+}
+
+// ReplaceBuffers discards both portions and continues with the
+// fresh values reader and writer, e.g. to recover after the old
+// storage was detected to be corrupt. The Writer itself and its
+// options are kept.
+//
+// reader is published as the next generation like with Swap,
+// but unconditionally and without any copy. ReplaceBuffers waits until
+// the Reader's of the old generation are done, the old portions are
+// never used again afterwards. reader and writer must not share
+// memory, similar to New.
+func (w *Writer[T]) ReplaceBuffers(reader, writer T) {
+	w.lockWriter()
+	defer w.unlockWriter()
+
+	old := w.current.Load()
+	w.checkDeadlock(old)
+	buffer := old.buffer
+	if w.writerBuffer > buffer {
+		buffer = w.writerBuffer
+	}
+	w.writerBuffer = buffer + 1
+	w.swapCurrent(w.nextGeneration(reader))
+	w.countSwap()
+	w.drain(old)
+
+	w.writerValue = writer
+	w.writerBuffer = buffer + 2
+	// both portions were provided by the caller
+	w.copiedLastSwap = true
+}
+
+// publish makes v the next generation. The old generation
+// has to be drained afterwards.
+func (w *Writer[T]) publish(v T) (newReader, oldReader *current[T]) {
+	if w.divergenceFn != nil {
+		w.checkDivergenceBeforeSwap()
+	}
+	newReader = w.nextGeneration(v)
+	oldReader = w.swapCurrent(newReader)
+	w.countSwap()
+	return newReader, oldReader
+}
+
+// swapCurrent publishes newReader in place of the previous generation,
+// which is returned and has to be drained afterwards. Every swap
+// publishes through swapCurrent, so the bookkeeping is the same.
+// Only countSwap is left to the caller, because a PendingSwap
+// counts as a swap once it is committed.
+func (w *Writer[T]) swapCurrent(newReader *current[T]) (oldReader *current[T]) {
+	oldReader = w.current.Swap(newReader)
+	oldReader.replacedBy = newReader.generation.Load()
+	w.retireWith(oldReader)
+	w.trace(GenerationPublished, newReader)
+	w.trace(GenerationRetired, oldReader)
+	w.addDraining(oldReader)
+	w.didPublish(oldReader.v, newReader.v)
+	return oldReader
+}
+
+// didPublish runs after every publication of new in place of old,
+// including the ones not going through swapCurrent.
+func (w *Writer[T]) didPublish(old, new T) {
+	w.addHistory(new)
+	w.broadcastPublished()
+	w.notifySubscribers(old, new)
+}
+
+// nextGeneration returns the generation following the
+// published one with v from the writer portion.
+func (w *Writer[T]) nextGeneration(v T) *current[T] {
+	c := &current[T]{v: v, buffer: w.writerBuffer, publishedAt: w.clock.Now()}
+	w.generations++
+	c.generation.Store(w.generations)
+	w.trace(GenerationCreated, c)
+	return c
+}
+
+// countSwap records a swap for WithMinSwapInterval, the swap rate
+// and the writes per swap.
+func (w *Writer[T]) countSwap() {
+	w.recordSwap()
+	w.publishedWrites()
+}
+
+func (w *Writer[T]) recordSwap() {
+	now := w.clock.Now()
+	w.swapRing.add(now)
+	w.lastSwap = now
+}
+
+// waitSwapInterval enforces WithMinSwapInterval. It reports
+// false if the swap should be skipped in favour of a later one.
+func (w *Writer[T]) waitSwapInterval() bool {
+	if w.minSwapInterval <= 0 || w.lastSwap.IsZero() {
+		return true
+	}
+	wait := w.minSwapInterval - w.since(w.lastSwap)
+	if wait <= 0 {
+		return true
+	}
+	if w.coalesceSwaps {
+		return false
+	}
+	w.clock.Sleep(wait)
+	return true
+}
+
+// drain waits until all Reader's of old are done.
+func (w *Writer[T]) drain(old *current[T]) {
+	testHook(hookSwapPublished)
+	var start time.Time
+	if w.onSwap != nil {
+		start = w.clock.Now()
+	}
+	blocked := !old.TryLock()
+	if blocked {
+		w.stats.blockedSwaps.Add(1)
+		old.blockedSince.Store(w.clock.Now().UnixNano())
+		testHook(hookSwapBlocked)
+		if w.swapDeadlineFn != nil {
+			stop := w.clock.AfterFunc(w.swapDeadline, func() {
+				w.swapDeadlineFn(w.swapStall(old))
+			})
+			defer stop()
+		}
+		old.Lock()
+		w.lastSwapWait.Store(w.clock.Now().UnixNano() - old.blockedSince.Load())
+		old.blockedSince.Store(0)
+	} else {
+		w.lastSwapWait.Store(0)
+	}
+	_ = "noop" // silence static analysis
+	old.Unlock()
+	w.stats.swaps.Add(1)
+	w.lastSwapBlocked.Store(blocked)
+	w.removeDraining(old)
+	w.reclaimRetired(old)
+	if w.onSwap != nil && w.swapSampler.sample() {
+		w.onSwap(SwapInfo{
+			Name:       w.name,
+			Generation: old.replacedBy,
+			Sequence:   swapSequence.Add(1),
+			Waited:     w.since(start),
+		})
+	}
+	testHook(hookSwapDrained)
+}
+
+func (w *Writer[T]) addDraining(c *current[T]) {
+	w.drainingMu.Lock()
+	defer w.drainingMu.Unlock()
+	w.draining = append(w.draining, c)
+}
+
+func (w *Writer[T]) removeDraining(c *current[T]) {
+	w.drainingMu.Lock()
+	defer w.drainingMu.Unlock()
+	for i, d := range w.draining {
+		if d == c {
+			w.draining = append(w.draining[:i], w.draining[i+1:]...)
+			return
+		}
+	}
+}
+
+// LastSwapBlocked reports whether the most recently finished
+// swap had to wait for old Reader's. This is a cheap signal
+// for self-tuning writers, e.g. to swap less often.
+//
+// Calling LastSwapBlocked is threadsafe.
+func (w *Writer[T]) LastSwapBlocked() bool {
+	return w.lastSwapBlocked.Load()
+}
+
+// Healthy reports whether the Writer is not stuck,
+// that is false if a Swap is currently waiting for
+// old Reader's for longer than maxSwapWait.
+// Healthy is true if no Swap is in progress.
+//
+// Calling Healthy is threadsafe.
+func (w *Writer[T]) Healthy(maxSwapWait time.Duration) bool {
+	w.drainingMu.Lock()
+	defer w.drainingMu.Unlock()
+	for _, c := range w.draining {
+		since := c.blockedSince.Load()
+		if since != 0 && w.since(time.Unix(0, since)) > maxSwapWait {
+			return false
+		}
+	}
+	return true
+}
+
+// MemoryFootprint returns the sum of size over the published value
+// and the values of all generations, which are still retained
+// because old Reader's are not done yet. It is meant for monitoring
+// whether leaked Reader's keep memory alive, not for the hot path.
+// The writer portion is not included.
+//
+// Calling MemoryFootprint is threadsafe.
+func (w *Writer[T]) MemoryFootprint(size func(T) int) int {
+	r := w.Reader()
+	n := size(r.Get())
+	r.Done()
+
+	w.drainingMu.Lock()
+	defer w.drainingMu.Unlock()
+	for _, c := range w.draining {
+		n += size(c.v)
+	}
+	return n
+}
+
+// DrainCurrent waits until the generation, which is published at the
+// time of the call, has no active Reader's. In contrast to the writer
+// methods it can be called from any goroutine, e.g. to coordinate
+// a graceful shutdown.
+//
+// New Reader's can still acquire the generation while waiting,
+// so DrainCurrent might never return unless new Reader's
+// are prevented by other means.
+//
+// Calling DrainCurrent is threadsafe.
+func (w *Writer[T]) DrainCurrent() {
+	c := w.current.Load()
+	for i := 0; c.readers.Load() != 0; i++ {
+		w.pollBackoff(i)
+	}
+}
+
+// drainProgressInterval is the interval of DrainWithProgress.
+const drainProgressInterval = 100 * time.Millisecond
+
+// DrainWithProgress is like DrainCurrent, but additionally waits for
+// the generations that are still being drained by a Swap. While
+// waiting, fn is called periodically with the number of remaining
+// Reader's, e.g. to log progress during a shutdown.
+//
+// Calling DrainWithProgress is threadsafe.
+func (w *Writer[T]) DrainWithProgress(fn func(remaining int64)) {
+	generations := []*current[T]{w.current.Load()}
+	w.drainingMu.Lock()
+	generations = append(generations, w.draining...)
+	w.drainingMu.Unlock()
+
+	remaining := func() int64 {
+		var n int64
+		for _, c := range generations {
+			n += c.readers.Load()
+		}
+		return n
+	}
+	if remaining() == 0 {
+		return
+	}
+	next := w.clock.Now().Add(drainProgressInterval)
+	for i := 0; ; i++ {
+		n := remaining()
+		if n == 0 {
+			return
+		}
+		if now := w.clock.Now(); !now.Before(next) {
+			fn(n)
+			next = now.Add(drainProgressInterval)
+		}
+		w.pollBackoff(i)
+	}
+}
+
+// pollBackoff is used between polling attempts.
+func (w *Writer[T]) pollBackoff(attempt int) {
+	if attempt < 16 {
+		runtime.Gosched()
+		return
+	}
+	w.clock.Sleep(time.Millisecond)
+}
@@ -1,10 +1,15 @@
 package readerwriter
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"runtime"
+	"sort"
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 )
 
 // to prevent possible optimizations
@@ -53,3 +58,966 @@ func TestReaderWriter(t *testing.T) {
 		r.Done()
 	}
 }
+
+func TestReadOrStale(t *testing.T) {
+	w := New(1, 2)
+	if v := w.ReadOrStale(time.Millisecond, -1); v != 1 {
+		t.Fatalf("got %d, want 1", v)
+	}
+	w.Swap()
+	if v := w.ReadOrStale(0, -1); v != 2 {
+		t.Fatalf("got %d, want 2", v)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r, err := w.ReaderContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Done()
+}
+
+func TestMaxReadersWarn(t *testing.T) {
+	var fired []int64
+	w := New(0, 0, WithMaxReadersWarn[int](2, func(count int64) {
+		fired = append(fired, count)
+	}))
+
+	var readers []*Reader[int]
+	for i := 0; i < 4; i++ {
+		readers = append(readers, w.Reader())
+	}
+	if got := w.ActiveReaders(); got != 4 {
+		t.Fatalf("active readers: got %d, want 4", got)
+	}
+	for _, r := range readers {
+		r.Done()
+	}
+	w.Reader().Done()
+	if len(fired) != 1 || fired[0] != 3 {
+		t.Fatalf("fired: got %v, want [3]", fired)
+	}
+}
+
+func TestMaxReadersWarnSwap(t *testing.T) {
+	var fired []int64
+	w := New(0, 0, WithMaxReadersWarn[int](1, func(count int64) {
+		fired = append(fired, count)
+	}))
+
+	var readers []*Reader[int]
+	for i := 0; i < 2; i++ {
+		readers = append(readers, w.Reader())
+	}
+	swapped := w.SwapAsync()
+	for i := 0; i < 2; i++ {
+		readers = append(readers, w.Reader())
+	}
+	for _, r := range readers {
+		r.Done()
+	}
+	<-swapped
+	if len(fired) != 1 || fired[0] != 2 {
+		t.Fatalf("fired: got %v, want [2]", fired)
+	}
+
+	readers = append(readers[:0], w.Reader(), w.Reader())
+	for _, r := range readers {
+		r.Done()
+	}
+	if len(fired) != 2 || fired[1] != 2 {
+		t.Fatalf("fired: got %v, want [2 2] after falling back", fired)
+	}
+}
+
+func TestTryGetWriter(t *testing.T) {
+	w := New(1, 2)
+	if v, ok := w.TryGetWriter(); !ok || v != 2 {
+		t.Fatalf("got %d, %t, want 2, true", v, ok)
+	}
+	p := w.BeginSwap()
+	if _, ok := w.TryGetWriter(); ok {
+		t.Fatal("TryGetWriter succeeded during a pending swap")
+	}
+	p.Commit()
+}
+
+func TestTryGetWriterNoWriterCheck(t *testing.T) {
+	w := New(1, 2, WithNoWriterCheck[int]())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			w.Set(i)
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if _, ok := w.TryGetWriter(); ok {
+			t.Fatal("TryGetWriter succeeded without the writer check")
+		}
+	}
+	<-done
+}
+
+func TestWarm(t *testing.T) {
+	w := New(1, 2)
+	w.Warm()
+	r := w.Reader()
+	defer r.Done()
+	if r.Get() != 1 || w.Get() != 2 {
+		t.Fatalf("got reader %d and writer %d, want 1 and 2", r.Get(), w.Get())
+	}
+	if got := r.Generation(); got != 2 {
+		t.Fatalf("generation %d, want 2", got)
+	}
+}
+
+func TestWriterPanicked(t *testing.T) {
+	w := New(0, 0, WithValidator(func(int) error { panic("validator") }))
+	if w.WriterPanicked() {
+		t.Fatal("panicked before any swap")
+	}
+	func() {
+		defer func() {
+			if p := recover(); p != "validator" {
+				t.Fatalf("got panic %v, want validator", p)
+			}
+		}()
+		w.Swap()
+	}()
+	if !w.WriterPanicked() {
+		t.Fatal("panic in Swap not reported")
+	}
+	w.Set(1)
+
+	w = New(0, 0, WithPublishTransform(func(int) int { panic("transform") }))
+	func() {
+		defer func() {
+			if p := recover(); p != "transform" {
+				t.Fatalf("got panic %v, want transform", p)
+			}
+		}()
+		w.BeginSwap()
+	}()
+	if !w.WriterPanicked() {
+		t.Fatal("panic in BeginSwap not reported")
+	}
+	w.Set(1)
+
+	w = New(0, 0, WithDeadlockCheck[int]())
+	r := w.Reader()
+	defer r.Done()
+	p := w.BeginSwap()
+	func() {
+		defer func() {
+			if p := recover(); p != messageSwapWouldDeadlock {
+				t.Fatalf("got panic %v, want %q", p, messageSwapWouldDeadlock)
+			}
+		}()
+		p.Commit()
+	}()
+	if !w.WriterPanicked() {
+		t.Fatal("panic in Commit not reported")
+	}
+	w.Set(1)
+}
+
+func TestHasReaders(t *testing.T) {
+	w := New(0, 0)
+	if w.HasReaders() {
+		t.Fatal("has readers without a reader")
+	}
+	r := w.Reader()
+	if !w.HasReaders() {
+		t.Fatal("has no readers with a reader")
+	}
+	r.Done()
+	if w.HasReaders() {
+		t.Fatal("has readers after Done")
+	}
+}
+
+func copyMap(dst, src map[string]int) {
+	for k := range dst {
+		delete(dst, k)
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+func TestNewWithCopy(t *testing.T) {
+	w := NewWithCopy(map[string]int{}, map[string]int{}, copyMap)
+	w.Get()["foo"] = 1
+	w.Swap()
+	if w.Get()["foo"] != 1 {
+		t.Fatal("writer portion was not copied")
+	}
+
+	w.SetCopyFunc(nil)
+	w.Get()["bar"] = 2
+	w.Swap()
+	if _, ok := w.Get()["bar"]; ok {
+		t.Fatal("copy function was not replaced")
+	}
+}
+
+func TestStatsBlockedSwaps(t *testing.T) {
+	w := New(1, 2)
+	w.Swap()
+
+	r := w.Reader()
+	swapped := make(chan struct{})
+	go func() {
+		w.Swap()
+		close(swapped)
+	}()
+	for w.Stats().BlockedSwaps == 0 {
+		runtime.Gosched()
+	}
+	r.Done()
+	<-swapped
+
+	if got, want := w.Stats(), (Stats{Swaps: 2, BlockedSwaps: 1, WritesPerSwap: Histogram{0: 2}}); got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	if !w.LastSwapBlocked() {
+		t.Fatal("last swap not reported as blocked")
+	}
+	w.Swap()
+	if w.LastSwapBlocked() {
+		t.Fatal("last swap reported as blocked")
+	}
+}
+
+func TestSwapNoCopy(t *testing.T) {
+	w := NewWithCopy(map[string]int{}, map[string]int{}, copyMap)
+	w.Get()["foo"] = 1
+	w.SwapNoCopy()
+	if _, ok := w.Get()["foo"]; ok {
+		t.Fatal("writer portion was copied")
+	}
+}
+
+func TestHealthy(t *testing.T) {
+	w := New(1, 2)
+	if !w.Healthy(0) {
+		t.Fatal("idle writer is unhealthy")
+	}
+
+	r := w.Reader()
+	swapped := make(chan struct{})
+	go func() {
+		w.Swap()
+		close(swapped)
+	}()
+	for w.Healthy(time.Millisecond) {
+		time.Sleep(time.Millisecond)
+	}
+	if !w.Healthy(time.Hour) {
+		t.Fatal("unhealthy below maxSwapWait")
+	}
+	r.Done()
+	<-swapped
+	if !w.Healthy(0) {
+		t.Fatal("unhealthy after swap")
+	}
+}
+
+func TestSwapIfGeneration(t *testing.T) {
+	w := New(1, 2)
+	r := w.Reader()
+	g := r.Generation()
+	r.Done()
+
+	if !w.SwapIfGeneration(g) {
+		t.Fatal("swap with current generation failed")
+	}
+	if w.SwapIfGeneration(g) {
+		t.Fatal("swap with old generation succeeded")
+	}
+	r = w.Reader()
+	if r.Generation() != g+1 || r.Get() != 2 {
+		t.Fatalf("got generation %d with %d", r.Generation(), r.Get())
+	}
+	r.Done()
+}
+
+func TestVerify(t *testing.T) {
+	w := NewWithCopy([]int{0}, []int{0}, func(dst, src []int) { copy(dst, src) })
+	equal := func(reader, writer []int) error {
+		if reader[0] != writer[0] {
+			return fmt.Errorf("reader %d, writer %d", reader[0], writer[0])
+		}
+		return nil
+	}
+	w.Get()[0] = 1
+	if err := w.Verify(equal); err == nil {
+		t.Fatal("Verify succeeded with different portions")
+	}
+	w.Swap()
+	if err := w.Verify(equal); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadWithGeneration(t *testing.T) {
+	w := New(1, 2)
+	w.Swap()
+	v, generation, release := w.ReadWithGeneration()
+	if v != 2 || generation != 1 {
+		t.Fatalf("got %d of generation %d, want 2 of generation 1", v, generation)
+	}
+	release()
+	release()
+	if n := w.ActiveReaders(); n != 0 {
+		t.Fatalf("%d active readers after release", n)
+	}
+}
+
+func TestReaderPtr(t *testing.T) {
+	type config struct {
+		name  string
+		large [1 << 10]int
+	}
+	w := New(config{name: "a"}, config{name: "b"})
+	r := w.Reader()
+	if got := r.Ptr().name; got != "a" {
+		t.Fatalf("got %q, want %q", got, "a")
+	}
+	if r.Ptr() != r.Ptr() {
+		t.Fatal("Ptr returned different pointers")
+	}
+	r.Done()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Ptr after Done did not panic")
+		}
+	}()
+	r.Ptr()
+}
+
+func TestCurrentGeneration(t *testing.T) {
+	w := New(0, 0)
+	w.Swap()
+	if !w.TryFastPublish(1) {
+		t.Fatal("TryFastPublish failed without readers")
+	}
+	r := w.Reader()
+	defer r.Done()
+	if got, want := w.CurrentGeneration(), r.Generation(); got != 2 || got != want {
+		t.Fatalf("current generation %d, reader generation %d, want 2", got, want)
+	}
+}
+
+func TestGenerationBefore(t *testing.T) {
+	w := New(0, 0)
+	older := w.Reader()
+	swapped := w.SwapAsync()
+	defer func() { <-swapped }()
+	defer older.Done()
+	newer := w.Reader()
+	defer newer.Done()
+
+	if !older.GenerationBefore(newer) {
+		t.Fatal("older reader not before newer reader")
+	}
+	if newer.GenerationBefore(older) || older.GenerationBefore(older) {
+		t.Fatal("newer reader before older reader")
+	}
+}
+
+func TestPublishBarrier(t *testing.T) {
+	w := New(1, 2)
+	w.PublishBarrier()
+	r := w.Reader()
+	defer r.Done()
+	if r.Generation() != 0 || r.Get() != 1 || w.Stats().Swaps != 0 {
+		t.Fatal("PublishBarrier changed the published value")
+	}
+}
+
+func TestSwapThen(t *testing.T) {
+	w := New(1, 0)
+	w.Set(2)
+	w.SwapThen(func(reclaimed int) int { return reclaimed * 10 })
+	if got := w.Get(); got != 10 {
+		t.Fatalf("writer portion %d, want 10", got)
+	}
+	r := w.Reader()
+	defer r.Done()
+	if got := r.Get(); got != 2 {
+		t.Fatalf("published %d, want 2", got)
+	}
+}
+
+func TestSwapWithBuffer(t *testing.T) {
+	w := New([]int{1}, []int{1})
+	w.Set([]int{2})
+	old := w.SwapWithBuffer([]int{3})
+	if len(old) != 1 || old[0] != 1 {
+		t.Fatalf("got old buffer %v, want [1]", old)
+	}
+	if got := w.Get(); len(got) != 1 || got[0] != 3 {
+		t.Fatalf("writer portion %v, want [3]", got)
+	}
+	r := w.Reader()
+	defer r.Done()
+	if got := r.Get(); len(got) != 1 || got[0] != 2 {
+		t.Fatalf("published %v, want [2]", got)
+	}
+}
+
+func TestRenew(t *testing.T) {
+	w := New(1, 2)
+	r := w.Reader()
+	if !r.Renew() || r.Get() != 1 {
+		t.Fatal("Renew without a Swap changed the Reader")
+	}
+
+	swapped := w.SwapAsync()
+	for w.CurrentGeneration() != 1 {
+		runtime.Gosched()
+	}
+	if r.Renew() {
+		t.Fatal("Renew after a Swap reported true")
+	}
+	<-swapped
+	if r.Generation() != 1 || r.Get() != 2 {
+		t.Fatalf("got generation %d with %d, want generation 1 with 2", r.Generation(), r.Get())
+	}
+	r.Done()
+	if n := w.ActiveReaders(); n != 0 {
+		t.Fatalf("got %d active readers, want 0", n)
+	}
+}
+
+func TestReplaceBuffers(t *testing.T) {
+	w := New([]int{1}, []int{1})
+	old := w.Reader()
+	replaced := make(chan struct{})
+	go func() {
+		defer close(replaced)
+		w.ReplaceBuffers([]int{2}, []int{3})
+	}()
+	select {
+	case <-replaced:
+		t.Fatal("ReplaceBuffers did not wait for the old reader")
+	case <-time.After(10 * time.Millisecond):
+	}
+	if got := old.Get()[0]; got != 1 {
+		t.Fatalf("old reader got %d, want 1", got)
+	}
+	old.Done()
+	<-replaced
+
+	r := w.Reader()
+	defer r.Done()
+	if got := r.Get()[0]; got != 2 {
+		t.Fatalf("reader got %d, want 2", got)
+	}
+	if got := w.Get()[0]; got != 3 {
+		t.Fatalf("writer got %d, want 3", got)
+	}
+	if got := r.Generation(); got != 1 {
+		t.Fatalf("generation %d, want 1", got)
+	}
+}
+
+func TestOnReadOnSwap(t *testing.T) {
+	var reads []uint64
+	var swaps []SwapInfo
+	w := New(0, 0,
+		WithName[int]("counter"),
+		WithOnRead[int](func(generation uint64) { reads = append(reads, generation) }),
+		WithOnSwap[int](func(info SwapInfo) { swaps = append(swaps, info) }),
+	)
+	w.Reader().Done()
+	w.Swap()
+	w.Reader().Done()
+
+	if got, want := fmt.Sprint(reads), "[0 1]"; got != want {
+		t.Fatalf("reads %s, want %s", got, want)
+	}
+	if len(swaps) != 1 || swaps[0].Name != "counter" || swaps[0].Generation != 1 {
+		t.Fatalf("unexpected swaps %+v", swaps)
+	}
+}
+
+func TestSwapSequence(t *testing.T) {
+	var swaps []SwapInfo
+	onSwap := WithOnSwap[int](func(info SwapInfo) { swaps = append(swaps, info) })
+	a := New(0, 0, WithName[int]("a"), onSwap)
+	b := New(0, 0, WithName[int]("b"), onSwap)
+	a.Swap()
+	b.Swap()
+	a.Swap()
+
+	if len(swaps) != 3 {
+		t.Fatalf("got %d swaps, want 3", len(swaps))
+	}
+	for i := 1; i < len(swaps); i++ {
+		if swaps[i].Sequence <= swaps[i-1].Sequence {
+			t.Fatalf("sequence not increasing: %+v", swaps)
+		}
+	}
+}
+
+func TestWithReadCopy(t *testing.T) {
+	w := New([]int{1}, []int{1}, WithReadCopy(func(s []int) []int {
+		return append([]int(nil), s...)
+	}))
+	r := w.Reader()
+	r.Get()[0] = 2
+	(*r.Ptr())[0] = 3
+	if got := r.Get()[0]; got != 1 {
+		t.Fatalf("published value modified to %d", got)
+	}
+	r.Done()
+}
+
+func TestPublishTransform(t *testing.T) {
+	w := New([]string{}, []string{}, WithPublishTransform(func(writer []string) []string {
+		sorted := append([]string(nil), writer...)
+		sort.Strings(sorted)
+		return sorted
+	}))
+	w.Set([]string{"b", "c", "a"})
+	w.Swap()
+
+	r := w.Reader()
+	if got, want := fmt.Sprint(r.Get()), "[a b c]"; got != want {
+		t.Fatalf("published %s, want %s", got, want)
+	}
+	r.Done()
+	if got, want := fmt.Sprint(w.Get()), "[b c a]"; got != want {
+		t.Fatalf("writer portion %s, want %s", got, want)
+	}
+}
+
+func TestPublishTransformFastPublish(t *testing.T) {
+	w := New([]string{}, []string{}, WithPublishTransform(func(writer []string) []string {
+		sorted := append([]string(nil), writer...)
+		sort.Strings(sorted)
+		return sorted
+	}))
+	read := func() string {
+		r := w.Reader()
+		defer r.Done()
+		return fmt.Sprint(r.Get())
+	}
+
+	if !w.TryFastPublish([]string{"b", "a"}) {
+		t.Fatal("TryFastPublish failed without readers")
+	}
+	if got, want := read(), "[a b]"; got != want {
+		t.Fatalf("TryFastPublish published %s, want %s", got, want)
+	}
+	if _, err := w.SmartPublish([]string{"d", "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := read(), "[c d]"; got != want {
+		t.Fatalf("SmartPublish published %s, want %s", got, want)
+	}
+	if got, want := fmt.Sprint(w.Get()), "[d c]"; got != want {
+		t.Fatalf("writer portion %s, want %s", got, want)
+	}
+	if got := w.Stats().Swaps; got != 0 {
+		t.Fatalf("%d swaps without readers, want 0", got)
+	}
+}
+
+func TestSwapAsync(t *testing.T) {
+	w := New(1, 2)
+	r := w.Reader()
+	first := w.SwapAsync()
+	second := w.SwapAsync()
+
+	select {
+	case <-first:
+		t.Fatal("first swap done while its reader is active")
+	case <-second:
+	}
+	if got := w.ActiveReaders(); got != 1 {
+		t.Fatalf("active readers: got %d, want 1", got)
+	}
+	r.Done()
+	<-first
+	r = w.Reader()
+	defer r.Done()
+	if r.Get() != 1 || r.Generation() != 2 {
+		t.Fatalf("got %d with generation %d", r.Get(), r.Generation())
+	}
+}
+
+func TestSetIfChanged(t *testing.T) {
+	w := New(0, 1)
+	equal := func(a, b int) bool { return a == b }
+	if w.SetIfChanged(1, equal) {
+		t.Fatal("equal value reported as changed")
+	}
+	if !w.SetIfChanged(2, equal) || w.Get() != 2 {
+		t.Fatal("changed value not set")
+	}
+}
+
+func TestSmartPublish(t *testing.T) {
+	w := NewWithCopy([]int{0}, []int{0}, func(dst, src []int) { copy(dst, src) })
+	read := func() int {
+		r := w.Reader()
+		defer r.Done()
+		return r.Get()[0]
+	}
+
+	w.SmartPublish([]int{1})
+	if got := read(); got != 1 {
+		t.Fatalf("got %d, want 1", got)
+	}
+	if got := w.Stats().Swaps; got != 0 {
+		t.Fatalf("%d swaps without readers, want 0", got)
+	}
+
+	r := w.Reader()
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		w.SmartPublish([]int{2})
+	}()
+	for w.CurrentGeneration() != 2 {
+		runtime.Gosched()
+	}
+	if got := r.Get()[0]; got != 1 {
+		t.Fatalf("held reader got %d, want 1", got)
+	}
+	r.Done()
+	<-published
+	if got := read(); got != 2 {
+		t.Fatalf("got %d, want 2", got)
+	}
+	if got := w.Get()[0]; got != 2 {
+		t.Fatalf("writer portion %d, want 2", got)
+	}
+}
+
+func TestFlushForRead(t *testing.T) {
+	w := NewWithCopy(map[string]int{}, map[string]int{}, copyMap)
+	w.Get()["foo"] = 1
+	w.FlushForRead()
+
+	r := w.Reader()
+	defer r.Done()
+	if r.Get()["foo"] != 1 || w.Get()["foo"] != 1 {
+		t.Fatal("writer portion not flushed")
+	}
+	if w.Stats().BlockedSwaps != 0 {
+		t.Fatal("flush blocked without readers")
+	}
+}
+
+func TestMemoryFootprint(t *testing.T) {
+	w := New([]int{1, 2, 3}, []int{4})
+	size := func(v []int) int { return len(v) }
+	if got := w.MemoryFootprint(size); got != 3 {
+		t.Fatalf("got %d, want 3", got)
+	}
+
+	r := w.Reader()
+	done := w.SwapAsync()
+	if got := w.MemoryFootprint(size); got != 4 {
+		t.Fatalf("got %d, want 4", got)
+	}
+	r.Done()
+	<-done
+	if got := w.MemoryFootprint(size); got != 1 {
+		t.Fatalf("got %d, want 1", got)
+	}
+}
+
+func TestMinSwapInterval(t *testing.T) {
+	const interval = 20 * time.Millisecond
+	w := New(0, 1, WithMinSwapInterval[int](interval))
+	start := time.Now()
+	w.Swap()
+	w.Swap()
+	if elapsed := time.Since(start); elapsed < interval {
+		t.Fatalf("second swap after %v", elapsed)
+	}
+
+	w = New(0, 1, WithMinSwapInterval[int](time.Hour), WithSwapCoalescing[int]())
+	w.Swap()
+	w.Swap()
+	<-w.SwapAsync()
+	if got := w.Stats().Swaps; got != 1 {
+		t.Fatalf("swaps: got %d, want 1", got)
+	}
+}
+
+func TestDrainCurrent(t *testing.T) {
+	w := New(0, 1)
+	w.DrainCurrent()
+
+	r := w.Reader()
+	drained := make(chan struct{})
+	go func() {
+		w.DrainCurrent()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		t.Fatal("drained while a reader is active")
+	case <-time.After(10 * time.Millisecond):
+	}
+	r.Done()
+	<-drained
+}
+
+func TestReaderUserData(t *testing.T) {
+	var got any
+	w := New(0, 1, WithOnDone[int](func(generation uint64, userData any) {
+		got = userData
+	}))
+	w.Swap()
+	r := w.Reader()
+	r.SetUserData("request-1")
+	if r.UserData() != "request-1" {
+		t.Fatalf("got %v", r.UserData())
+	}
+	r.Done()
+	if got != "request-1" {
+		t.Fatalf("OnDone got %v", got)
+	}
+}
+
+func TestSwapE(t *testing.T) {
+	errNegative := errors.New("negative")
+	w := New(0, 0,
+		WithValidator(func(v int) error {
+			if v < 0 {
+				return errNegative
+			}
+			return nil
+		}),
+		WithDedup(func(a, b int) bool { return a == b }),
+	)
+
+	tests := []struct {
+		set    int
+		result SwapResult
+		err    error
+	}{
+		{0, SkippedDedup, nil},
+		{-1, SkippedValidation, errNegative},
+		{1, Published, nil},
+	}
+	for _, tt := range tests {
+		w.Set(tt.set)
+		result, err := w.SwapE()
+		if result != tt.result || !errors.Is(err, tt.err) {
+			t.Fatalf("set %d: got %v, %v; want %v, %v", tt.set, result, err, tt.result, tt.err)
+		}
+	}
+
+	w.Set(-1)
+	_, err := w.SwapE()
+	var swapErr *SwapError
+	if !errors.As(err, &swapErr) || swapErr.Phase != PhaseValidate || swapErr.Generation != 2 {
+		t.Fatalf("got %#v", err)
+	}
+}
+
+func TestTotalBytesCopied(t *testing.T) {
+	w := NewWithCopyN([]byte("foo"), []byte("bar"), func(dst, src []byte) int {
+		return copy(dst, src)
+	})
+	w.Swap()
+	w.Swap()
+	if got := w.Stats().TotalBytesCopied; got != 6 {
+		t.Fatalf("got %d, want 6", got)
+	}
+}
+
+func TestSuspendSwaps(t *testing.T) {
+	w := New(0, 1)
+	w.SuspendSwaps()
+	if result, _ := w.SwapE(); result != Suspended {
+		t.Fatalf("got %v, want %v", result, Suspended)
+	}
+	w.Swap()
+	if w.Stats().Swaps != 0 {
+		t.Fatal("swapped while suspended")
+	}
+	w.ResumeSwaps()
+	if w.Stats().Swaps != 1 {
+		t.Fatal("no coalesced swap on resume")
+	}
+	if result, _ := w.SwapE(); result != Published {
+		t.Fatalf("got %v, want %v", result, Published)
+	}
+}
+
+func TestTryFastPublish(t *testing.T) {
+	w := New(0, 1)
+	if !w.TryFastPublish(2) {
+		t.Fatal("fast publish without readers failed")
+	}
+	r := w.Reader()
+	if r.Get() != 2 || r.Generation() != 1 {
+		t.Fatalf("got %d with generation %d", r.Get(), r.Generation())
+	}
+	if w.TryFastPublish(3) {
+		t.Fatal("fast publish with active reader succeeded")
+	}
+	r.Done()
+	if w.Get() != 1 {
+		t.Fatal("writer portion changed")
+	}
+}
+
+func TestSmartPublishChecks(t *testing.T) {
+	w := New(0, 0, WithValidator(func(v int) error {
+		if v < 0 {
+			return errors.New("negative")
+		}
+		return nil
+	}))
+	if result, err := w.SmartPublish(-7); result != SkippedValidation || err == nil {
+		t.Fatalf("got %v, %v, want %v", result, err, SkippedValidation)
+	}
+	if got := w.CurrentGeneration(); got != 0 {
+		t.Fatalf("got generation %d, want 0", got)
+	}
+
+	w.SuspendSwaps()
+	if result, _ := w.SmartPublish(5); result != Suspended {
+		t.Fatalf("got %v, want %v", result, Suspended)
+	}
+	w.ResumeSwaps()
+	r := w.Reader()
+	defer r.Done()
+	if got := r.Get(); got != 5 {
+		t.Fatalf("got %d after ResumeSwaps, want 5", got)
+	}
+}
+
+func TestTryFastPublishChecks(t *testing.T) {
+	w := New(0, 0, WithValidator(func(v int) error {
+		if v < 0 {
+			return errors.New("negative")
+		}
+		return nil
+	}))
+	if w.TryFastPublish(-1) {
+		t.Fatal("fast publish skipped the validator")
+	}
+	w.SuspendSwaps()
+	if w.TryFastPublish(5) {
+		t.Fatal("fast publish while suspended succeeded")
+	}
+	w.ResumeSwaps()
+	if got := w.CurrentGeneration(); got != 0 {
+		t.Fatalf("got generation %d, want 0", got)
+	}
+}
+
+func TestDrainWithProgress(t *testing.T) {
+	w := New(0, 1)
+	r := w.Reader()
+	go func() {
+		time.Sleep(2 * drainProgressInterval)
+		r.Done()
+	}()
+
+	var reports []int64
+	w.DrainWithProgress(func(remaining int64) {
+		reports = append(reports, remaining)
+	})
+	if len(reports) == 0 || reports[0] != 1 {
+		t.Fatalf("got reports %v", reports)
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	w := New(map[string]int{}, map[string]int{})
+	if err := w.Reconfigure(WithName[map[string]int]("foo")); err != ErrNotReconfigurable {
+		t.Fatalf("got %v, want %v", err, ErrNotReconfigurable)
+	}
+	if err := w.Reconfigure(WithCopy(copyMap)); err != nil {
+		t.Fatal(err)
+	}
+	w.Get()["foo"] = 1
+	w.Swap()
+	if w.Get()["foo"] != 1 {
+		t.Fatal("copy function not applied")
+	}
+	err := w.Reconfigure(
+		WithPublishTransform(func(m map[string]int) map[string]int { return m }),
+		WithAdaptiveSwap[map[string]int](DefaultAdaptivePolicy(1)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReaderForRequest(t *testing.T) {
+	w := New(1, 2)
+	r := w.ReaderForRequest()
+	r.SetUserData("request")
+	swapped := make(chan struct{})
+	go func() {
+		w.Swap()
+		close(swapped)
+	}()
+	for w.CurrentGeneration() != 1 {
+		runtime.Gosched()
+	}
+	if r.Get() != 1 {
+		t.Fatal("reader lost its generation during the swap")
+	}
+	r.Done()
+	<-swapped
+
+	r = w.ReaderForRequest()
+	defer r.Done()
+	if r.Get() != 2 || r.UserData() != nil {
+		t.Fatalf("got %d with user data %v", r.Get(), r.UserData())
+	}
+}
+
+func BenchmarkReader(b *testing.B) {
+	w := New(1, 2)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			w.Reader().Done()
+		}
+	})
+}
+
+func BenchmarkSet(b *testing.B) {
+	for _, bc := range []struct {
+		name string
+		opts []Option[int]
+	}{
+		{"WriterCheck", nil},
+		{"NoWriterCheck", []Option[int]{WithNoWriterCheck[int]()}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			w := New(1, 2, bc.opts...)
+			for i := 0; i < b.N; i++ {
+				w.Set(i)
+			}
+		})
+	}
+}
+
+func BenchmarkReaderForRequest(b *testing.B) {
+	w := New(1, 2)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			w.ReaderForRequest().Done()
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package readerwritertest
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/erikfastermann/readerwriter"
+)
+
+// FakeSource is an in-memory readerwriter.Source for tests of code,
+// which depends on a Source instead of a concrete Writer. The test
+// controls the published value with Set and inspects the usage
+// with Reads.
+type FakeSource[T any] struct {
+	mu    sync.Mutex
+	w     *readerwriter.Writer[T]
+	reads atomic.Int64
+}
+
+var _ readerwriter.Source[int] = (*FakeSource[int])(nil)
+
+// NewFakeSource returns a FakeSource publishing v.
+func NewFakeSource[T any](v T) *FakeSource[T] {
+	var zero T
+	return &FakeSource[T]{w: readerwriter.New(v, zero)}
+}
+
+// Set publishes v. It waits until the Reader's
+// of the previous value are done.
+//
+// Calling Set is threadsafe.
+func (f *FakeSource[T]) Set(v T) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.w.Set(v)
+	f.w.SwapNoCopy()
+}
+
+// Reader implements readerwriter.Source.
+func (f *FakeSource[T]) Reader() *readerwriter.Reader[T] {
+	f.reads.Add(1)
+	return f.w.Reader()
+}
+
+// Read implements readerwriter.Source.
+func (f *FakeSource[T]) Read(fn func(T)) {
+	f.reads.Add(1)
+	f.w.Read(fn)
+}
+
+// Reads returns the number of calls to Reader and Read.
+func (f *FakeSource[T]) Reads() int64 {
+	return f.reads.Load()
+}
+
+// ActiveReaders returns the number of Reader's
+// that are not done yet, e.g. to detect leaks.
+func (f *FakeSource[T]) ActiveReaders() int64 {
+	return f.w.ActiveReaders()
+}
@@ -0,0 +1,31 @@
+package readerwritertest
+
+import (
+	"testing"
+
+	"github.com/erikfastermann/readerwriter"
+)
+
+func TestFakeSource(t *testing.T) {
+	f := NewFakeSource("a")
+	var s readerwriter.Source[string] = f
+
+	s.Read(func(v string) {
+		if v != "a" {
+			t.Fatalf("got %q, want %q", v, "a")
+		}
+	})
+	f.Set("b")
+	r := s.Reader()
+	if got := r.Get(); got != "b" {
+		t.Fatalf("got %q, want %q", got, "b")
+	}
+	r.Done()
+
+	if n := f.Reads(); n != 2 {
+		t.Fatalf("got %d reads, want 2", n)
+	}
+	if n := f.ActiveReaders(); n != 0 {
+		t.Fatalf("got %d active readers, want 0", n)
+	}
+}
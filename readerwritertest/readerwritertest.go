@@ -0,0 +1,94 @@
+// Package readerwritertest implements helpers for testing
+// code built on top of package readerwriter.
+package readerwritertest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/erikfastermann/readerwriter"
+)
+
+// FuzzRoundTrip checks that the copy function registered with w
+// (see readerwriter.NewWithCopy) keeps both portions consistent.
+//
+// For every byte of data the writer portion is set to mutate(v, b)
+// and w is swapped. Afterwards the published value and the
+// new writer portion have to be equal according to equal,
+// otherwise t fails.
+//
+// It is meant to be called from the function passed to testing.F.Fuzz
+// with the fuzzed data, but works with any data.
+func FuzzRoundTrip[T any](t testing.TB, w *readerwriter.Writer[T], data []byte, mutate func(v T, b byte) T, equal func(a, b T) bool) {
+	t.Helper()
+	for i, b := range data {
+		w.Set(mutate(w.Get(), b))
+		w.Swap()
+
+		r := w.Reader()
+		ok := equal(r.Get(), w.Get())
+		r.Done()
+		if !ok {
+			t.Fatalf("reader and writer portion differ after swap %d (byte %#x)", i, b)
+		}
+	}
+}
+
+// AssertDrains swaps w and fails t, if the swap does not finish
+// within timeout, e.g. because a Reader was not released.
+// The swap keeps running on a separate goroutine after a failure,
+// so w must not be used by the writer afterwards.
+//
+// AssertDrains is a writer method of w.
+func AssertDrains[T any](t testing.TB, w *readerwriter.Writer[T], timeout time.Duration) {
+	t.Helper()
+	swapped := make(chan struct{})
+	go func() {
+		defer close(swapped)
+		w.Swap()
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-swapped:
+	case <-timer.C:
+		t.Fatalf("swap blocked for more than %v by %d active readers", timeout, w.ActiveReaders())
+	}
+}
+
+// WithReader calls fn with the published value of w. The Reader
+// is released afterwards, or by a cleanup of t, if fn stops the
+// test with t.FailNow.
+func WithReader[T any](t testing.TB, w *readerwriter.Writer[T], fn func(T)) {
+	t.Helper()
+	r := w.Reader()
+	released := false
+	release := func() {
+		if !released {
+			released = true
+			r.Done()
+		}
+	}
+	t.Cleanup(release)
+	fn(r.Get())
+	release()
+}
+
+// ExpectPanic calls fn and fails t, if fn does not panic,
+// e.g. to check the misuse detection. It returns the
+// recovered value otherwise.
+func ExpectPanic(t testing.TB, fn func()) (recovered any) {
+	t.Helper()
+	panicked := true
+	func() {
+		defer func() {
+			recovered = recover()
+		}()
+		fn()
+		panicked = false
+	}()
+	if !panicked {
+		t.Fatalf("expected a panic")
+	}
+	return recovered
+}
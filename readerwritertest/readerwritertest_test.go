@@ -0,0 +1,110 @@
+package readerwritertest
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/erikfastermann/readerwriter"
+)
+
+type counts map[byte]int
+
+func copyCounts(dst, src counts) {
+	for k := range dst {
+		delete(dst, k)
+	}
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+func equalCounts(a, b counts) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+func FuzzCopyCounts(f *testing.F) {
+	f.Add([]byte("foobar"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		w := readerwriter.NewWithCopy(counts{}, counts{}, copyCounts)
+		FuzzRoundTrip(t, w, data, func(v counts, b byte) counts {
+			if b%4 == 0 {
+				delete(v, b/4)
+			} else {
+				v[b/4]++
+			}
+			return v
+		}, equalCounts)
+	})
+}
+
+// fakeTB records failures instead of failing the test.
+type fakeTB struct {
+	testing.TB
+	failed bool
+}
+
+func (t *fakeTB) Helper() {}
+
+func (t *fakeTB) Fatalf(format string, args ...any) {
+	t.failed = true
+	runtime.Goexit()
+}
+
+// run calls fn with t on a new goroutine,
+// so Fatalf can stop it.
+func (t *fakeTB) run(fn func(t *fakeTB)) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(t)
+	}()
+	<-done
+}
+
+func TestAssertDrains(t *testing.T) {
+	w := readerwriter.New(1, 2)
+	AssertDrains(t, w, time.Second)
+
+	r := w.Reader()
+	tb := &fakeTB{TB: t}
+	tb.run(func(tb *fakeTB) { AssertDrains(tb, w, time.Millisecond) })
+	if !tb.failed {
+		t.Fatal("AssertDrains did not fail with an active reader")
+	}
+	r.Done()
+}
+
+func TestWithReader(t *testing.T) {
+	w := readerwriter.New(1, 2)
+	WithReader(t, w, func(v int) {
+		if v != 1 {
+			t.Fatalf("got %d, want 1", v)
+		}
+	})
+	if n := w.ActiveReaders(); n != 0 {
+		t.Fatalf("%d active readers after WithReader", n)
+	}
+}
+
+func TestExpectPanic(t *testing.T) {
+	r := readerwriter.New(1, 2).Reader()
+	r.Done()
+	if p := ExpectPanic(t, func() { r.Done() }); p == nil {
+		t.Fatal("missing recovered value")
+	}
+
+	tb := &fakeTB{TB: t}
+	tb.run(func(tb *fakeTB) { ExpectPanic(tb, func() {}) })
+	if !tb.failed {
+		t.Fatal("ExpectPanic did not fail without a panic")
+	}
+}
@@ -0,0 +1,118 @@
+package readerwriter
+
+import "sync"
+
+const messageDuplicateName = "writer name already registered"
+
+// Registry is a set of Writer's, possibly of different types,
+// which can be quiesced together with Registry.Snapshot,
+// e.g. for a backup of several datasets.
+type Registry struct {
+	mu      sync.Mutex
+	members []registryMember
+}
+
+type registryMember interface {
+	name() string
+	// quiesce stops admitting new Reader's, waits until the published
+	// generation has no Reader's and pins it for the snapshot.
+	quiesce() (v any, resume func())
+}
+
+type registryWriter[T any] struct {
+	w *Writer[T]
+}
+
+func (m registryWriter[T]) name() string {
+	return m.w.name
+}
+
+func (m registryWriter[T]) quiesce() (any, func()) {
+	w := m.w
+	w.StopAdmitting()
+	w.DrainCurrent()
+	c := w.pin()
+	return c.v, func() {
+		c.readers.Add(-1)
+		c.RUnlock()
+		w.ResumeAdmitting()
+	}
+}
+
+// pin locks the published generation like a Reader,
+// regardless of StopAdmitting.
+func (w *Writer[T]) pin() *current[T] {
+	for attempt := 0; ; attempt++ {
+		c := w.current.Load()
+		if c.TryRLock() {
+			if c == w.current.Load() {
+				c.readers.Add(1)
+				return c
+			}
+			c.RUnlock()
+		}
+		w.backoff.Backoff(attempt, w.clock)
+	}
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds w to the members of reg under its name (see WithName),
+// which must be unique in reg. Register panics otherwise.
+//
+// Calling Register is threadsafe.
+func Register[T any](reg *Registry, w *Writer[T]) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	for _, m := range reg.members {
+		if m.name() == w.name {
+			panic(messageDuplicateName)
+		}
+	}
+	reg.members = append(reg.members, registryWriter[T]{w})
+}
+
+// Snapshot quiesces the members of reg one after another in the
+// order of registration: it stops admitting new Reader's like
+// StopAdmitting, waits until the published generation has no
+// Reader's and keeps it. Then fn is called with the kept values by
+// member name. Once fn returns, the members admit Reader's again
+// in reverse order. fn must not modify the values and must not
+// use them after returning.
+//
+// Every value is the published value at the time its member was
+// quiesced. A member swapping while later members are quiesced is
+// not reflected, but its swap blocks until fn returns, like for a
+// Reader. For values from a single instant the writer has to stop
+// swapping on its own.
+//
+// While Snapshot runs, Reader's of quiesced members are not admitted
+// (see StopAdmitting) and their swaps block, so fn should be short.
+// Reader's of several members must only be nested in the order of
+// registration, otherwise Snapshot deadlocks: it would wait for a
+// Reader, whose goroutine waits for admission of an earlier member.
+// For the same reason Snapshot must not be called while holding a
+// Reader of a member and the members must not use StopAdmitting
+// themselves.
+//
+// Snapshot calls are serialized. Calling Snapshot is threadsafe.
+func (reg *Registry) Snapshot(fn func(snapshots map[string]any)) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	snapshots := make(map[string]any, len(reg.members))
+	resumes := make([]func(), 0, len(reg.members))
+	defer func() {
+		for i := len(resumes) - 1; i >= 0; i-- {
+			resumes[i]()
+		}
+	}()
+	for _, m := range reg.members {
+		v, resume := m.quiesce()
+		resumes = append(resumes, resume)
+		snapshots[m.name()] = v
+	}
+	fn(snapshots)
+}
@@ -0,0 +1,51 @@
+package readerwriter
+
+import "testing"
+
+func TestRegistrySnapshot(t *testing.T) {
+	users := New(1, 1, WithName[int]("users"))
+	roles := New("admin", "admin", WithName[string]("roles"))
+	reg := NewRegistry()
+	Register(reg, users)
+	Register(reg, roles)
+
+	r := users.Reader()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reg.Snapshot(func(snapshots map[string]any) {
+			if snapshots["users"] != 1 || snapshots["roles"] != "admin" {
+				t.Errorf("unexpected snapshots %v", snapshots)
+			}
+			if _, ok := users.TryReader(); ok {
+				t.Error("reader admitted during snapshot")
+			}
+		})
+	}()
+	for !users.notAdmitting.Load() {
+	}
+	select {
+	case <-done:
+		t.Fatal("Snapshot did not wait for the active reader")
+	default:
+	}
+	r.Done()
+	<-done
+
+	r = users.Reader()
+	defer r.Done()
+	if r.Get() != 1 {
+		t.Fatalf("got %d, want 1", r.Get())
+	}
+}
+
+func TestRegisterDuplicateName(t *testing.T) {
+	reg := NewRegistry()
+	Register(reg, New(0, 0, WithName[int]("a")))
+	defer func() {
+		if p := recover(); p != messageDuplicateName {
+			t.Fatalf("got panic %v, want %q", p, messageDuplicateName)
+		}
+	}()
+	Register(reg, New(0, 0, WithName[int]("a")))
+}
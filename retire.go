@@ -0,0 +1,42 @@
+package readerwriter
+
+// WithOnReclaim calls fn for every object passed to Writer.Retire,
+// once no Reader can reference it anymore.
+func WithOnReclaim[T any](fn func(obj any)) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.onReclaim = fn
+	})
+}
+
+// Retire defers the cleanup of obj, which was removed from the
+// writer portion but might still be referenced by the published
+// value, e.g. an element of a collection, which escaped into
+// both portions.
+//
+// obj is passed to the WithOnReclaim callback, once the
+// generation published at the time of the call is drained,
+// i.e. during the next swap. The callback runs on the writer
+// goroutine, with SwapAsync on its draining goroutine.
+func (w *Writer[T]) Retire(obj any) {
+	w.lockWriter()
+	defer w.unlockWriter()
+	w.retired = append(w.retired, obj)
+}
+
+// retireWith hands the retired objects over to c,
+// which is drained next.
+func (w *Writer[T]) retireWith(c *current[T]) {
+	c.retired = append(c.retired, w.retired...)
+	w.retired = nil
+}
+
+func (w *Writer[T]) reclaimRetired(c *current[T]) {
+	retired := c.retired
+	c.retired = nil
+	if w.onReclaim == nil {
+		return
+	}
+	for _, obj := range retired {
+		w.onReclaim(obj)
+	}
+}
@@ -0,0 +1,27 @@
+package readerwriter
+
+import "testing"
+
+func TestRetire(t *testing.T) {
+	var reclaimed []any
+	w := New(0, 0, WithOnReclaim[int](func(obj any) {
+		reclaimed = append(reclaimed, obj)
+	}))
+	w.Retire("a")
+	r := w.Reader()
+	done := w.SwapAsync()
+	if len(reclaimed) != 0 {
+		t.Fatalf("reclaimed %v while a reader is active", reclaimed)
+	}
+	w.Retire("b")
+	r.Done()
+	<-done
+	if len(reclaimed) != 1 || reclaimed[0] != "a" {
+		t.Fatalf("reclaimed %v, want [a]", reclaimed)
+	}
+
+	w.Swap()
+	if len(reclaimed) != 2 || reclaimed[1] != "b" {
+		t.Fatalf("reclaimed %v, want [a b]", reclaimed)
+	}
+}
@@ -0,0 +1,63 @@
+package readerwriter
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// WithSampling calls the WithOnRead and WithOnSwap hooks only for a
+// fraction rate of the events, e.g. 0.01 for every 100th Reader.
+// Reads and swaps are sampled independently. WithGenerationTrace
+// is sampled per generation, so all events of a sampled generation
+// are reported. A rate <= 0 disables the hooks, a rate >= 1 calls
+// them for every event.
+//
+// Sampling is counter based, so it is cheap, but not random: every
+// n-th event is reported, with n being 1/rate rounded. Anything
+// derived from the sampled events is an estimate and has to be
+// scaled by n.
+func WithSampling[T any](rate float64) Option[T] {
+	return newOption(func(w *Writer[T]) {
+		w.readSampler = newSampler(rate)
+		w.swapSampler = newSampler(rate)
+		w.traceSampler = newSampler(rate)
+	})
+}
+
+// sampler reports every n-th event. A nil *sampler reports all events.
+type sampler struct {
+	n      uint64
+	events atomic.Uint64
+}
+
+func newSampler(rate float64) *sampler {
+	if rate >= 1 {
+		return nil
+	}
+	if rate <= 0 {
+		return &sampler{n: 0}
+	}
+	return &sampler{n: uint64(math.Round(1 / rate))}
+}
+
+func (s *sampler) sample() bool {
+	if s == nil {
+		return true
+	}
+	if s.n == 0 {
+		return false
+	}
+	return s.events.Add(1)%s.n == 0
+}
+
+// sampleGeneration is like sample, but reports
+// every n-th generation instead of every n-th call.
+func (s *sampler) sampleGeneration(generation uint64) bool {
+	if s == nil {
+		return true
+	}
+	if s.n == 0 {
+		return false
+	}
+	return generation%s.n == 0
+}
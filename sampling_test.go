@@ -0,0 +1,50 @@
+package readerwriter
+
+import "testing"
+
+func TestSampling(t *testing.T) {
+	var reads, swaps int
+	w := New(0, 0,
+		WithSampling[int](0.25),
+		WithOnRead[int](func(uint64) { reads++ }),
+		WithOnSwap[int](func(SwapInfo) { swaps++ }),
+	)
+	for i := 0; i < 8; i++ {
+		w.Reader().Done()
+		w.Swap()
+	}
+	if reads != 2 || swaps != 2 {
+		t.Fatalf("got %d reads and %d swaps, want 2 each", reads, swaps)
+	}
+}
+
+func TestSamplingDisabled(t *testing.T) {
+	called := false
+	w := New(0, 0,
+		WithSampling[int](0),
+		WithOnRead[int](func(uint64) { called = true }),
+	)
+	w.Reader().Done()
+	if called {
+		t.Fatal("hook called with rate 0")
+	}
+}
+
+func TestSamplingTrace(t *testing.T) {
+	generations := make(map[uint64]int)
+	w := New(0, 0,
+		WithSampling[int](0.5),
+		WithGenerationTrace[int](func(e GenerationEvent) { generations[e.Generation]++ }),
+	)
+	for i := 0; i < 4; i++ {
+		w.Swap()
+	}
+	for g := range generations {
+		if g%2 != 0 {
+			t.Fatalf("generation %d not sampled, got %v", g, generations)
+		}
+	}
+	if len(generations) == 0 {
+		t.Fatal("no generation sampled")
+	}
+}
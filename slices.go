@@ -0,0 +1,61 @@
+package readerwriter
+
+import "fmt"
+
+// NewSlice returns a new Writer with empty reader and writer
+// portions that have independent backing arrays of the given capacity.
+func NewSlice[E any](capacity int, opts ...Option[[]E]) *Writer[[]E] {
+	return New(make([]E, 0, capacity), make([]E, 0, capacity), opts...)
+}
+
+// Slice returns v[from:to] of the slice held by r, without copying.
+// The capacity of the result is limited to its length, so appending
+// to it never writes to the shared buffer.
+//
+// Like the result of Get, the returned slice must not be modified
+// and must not be used after calling Done. Slice panics if r is done
+// or if the bounds are out of range.
+func Slice[E any](r *Reader[[]E], from, to int) []E {
+	v := r.Get()
+	if from < 0 || to < from || to > len(v) {
+		panic(fmt.Sprintf("slice bounds [%d:%d] out of range with length %d", from, to, len(v)))
+	}
+	return v[from:to:to]
+}
+
+// CloneSlice returns an independent copy of the published slice of w.
+//
+// Calling CloneSlice is threadsafe.
+func CloneSlice[E any](w *Writer[[]E]) []E {
+	r := w.Reader()
+	defer r.Done()
+	return append([]E(nil), r.Get()...)
+}
+
+// ReadCopy copies the published slice of w to dst, growing it if
+// necessary, and returns the result. It does not share memory with the
+// published slice, so it stays valid after swaps. Reusing the result
+// as dst avoids allocations once it is large enough.
+//
+// Calling ReadCopy is threadsafe.
+func ReadCopy[E any](w *Writer[[]E], dst []E) []E {
+	r := w.ReaderForRequest()
+	defer r.Done()
+	return append(dst[:0], r.Get()...)
+}
+
+// Aggregate folds the published slice of w with fn, starting with
+// init, e.g. to compute a sum or a maximum. All elements are from
+// the same generation, because a single Reader is held while
+// aggregating.
+//
+// Calling Aggregate is threadsafe.
+func Aggregate[E, R any](w *Writer[[]E], fn func(acc R, elem E) R, init R) R {
+	r := w.Reader()
+	defer r.Done()
+	acc := init
+	for _, e := range r.Get() {
+		acc = fn(acc, e)
+	}
+	return acc
+}
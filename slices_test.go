@@ -0,0 +1,82 @@
+package readerwriter
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSlice(t *testing.T) {
+	w := New([]byte("foobar"), nil)
+	r := w.Reader()
+	defer r.Done()
+
+	s := Slice(r, 3, 6)
+	if !bytes.Equal(s, []byte("bar")) || cap(s) != 3 {
+		t.Fatalf("got %q with cap %d", s, cap(s))
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic")
+			}
+		}()
+		Slice(r, 4, 7)
+	}()
+}
+
+func TestNewSlice(t *testing.T) {
+	w := NewSlice[int](4)
+	w.Set(append(w.Get(), 1, 2))
+	w.Swap()
+	w.Set(append(w.Get(), 3))
+
+	r := w.Reader()
+	defer r.Done()
+	if v := r.Get(); len(v) != 2 || v[0] != 1 || v[1] != 2 || cap(v) != 4 {
+		t.Fatalf("reader portion aliased: %v", v)
+	}
+}
+
+func TestPublishAndReset(t *testing.T) {
+	w := NewSlice[int](4)
+	w.Set(append(w.Get(), 1, 2))
+	reclaimed := w.PublishAndReset(func(s []int) []int { return s[:0] })
+	if len(reclaimed) != 0 || cap(reclaimed) != 4 {
+		t.Fatalf("got %v with cap %d", reclaimed, cap(reclaimed))
+	}
+	r := w.Reader()
+	defer r.Done()
+	if v := r.Get(); len(v) != 2 {
+		t.Fatalf("published %v", v)
+	}
+}
+
+func TestCloneSlice(t *testing.T) {
+	w := New([]int{1, 2}, nil)
+	c := CloneSlice(w)
+	c[0] = 3
+	if c := CloneSlice(w); len(c) != 2 || c[0] != 1 {
+		t.Fatalf("clone aliases the published slice: %v", c)
+	}
+}
+
+func TestReadCopy(t *testing.T) {
+	w := New([]int{1, 2, 3}, nil)
+	buf := make([]int, 0, 3)
+	buf = ReadCopy(w, buf)
+	if len(buf) != 3 || buf[2] != 3 {
+		t.Fatalf("got %v", buf)
+	}
+	if n := testing.AllocsPerRun(10, func() { buf = ReadCopy(w, buf) }); n > 1 {
+		t.Fatalf("got %v allocations", n)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	w := New([]int{3, 1, 2}, nil)
+	sum := Aggregate(w, func(acc, e int) int { return acc + e }, 0)
+	if sum != 6 {
+		t.Fatalf("got sum %d, want 6", sum)
+	}
+}
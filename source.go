@@ -0,0 +1,23 @@
+package readerwriter
+
+// Source contains the read side of a Writer. Code that only
+// reads can depend on a Source instead of a *Writer, so tests
+// can substitute a fake, see package readerwritertest.
+type Source[T any] interface {
+	// Reader acquires a Reader, see Writer.Reader.
+	Reader() *Reader[T]
+	// Read calls fn with the published value, see Writer.Read.
+	Read(fn func(T))
+}
+
+var _ Source[int] = (*Writer[int])(nil)
+
+// Read calls fn with the published value. The Reader is held
+// until fn returns, so the value must not be used afterwards.
+//
+// Calling Read is threadsafe.
+func (w *Writer[T]) Read(fn func(T)) {
+	r := w.Reader()
+	defer r.Done()
+	fn(r.Get())
+}
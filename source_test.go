@@ -0,0 +1,15 @@
+package readerwriter
+
+import "testing"
+
+func TestRead(t *testing.T) {
+	var s Source[int] = New(1, 0)
+	var got int
+	s.Read(func(v int) { got = v })
+	if got != 1 {
+		t.Fatalf("got %d, want 1", got)
+	}
+	if n := s.(*Writer[int]).ActiveReaders(); n != 0 {
+		t.Fatalf("got %d active readers, want 0", n)
+	}
+}
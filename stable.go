@@ -0,0 +1,34 @@
+package readerwriter
+
+import "errors"
+
+// ErrSuperseded is returned by ReadStable, if every attempt
+// was superseded by a swap.
+var ErrSuperseded = errors.New("snapshot superseded by a swap")
+
+// ReadStable calls fn with the published value and returns its
+// results, if no swap published a newer value while fn was running.
+// Otherwise fn is called again with the newer value, at most
+// maxAttempts times in total. If the last attempt was superseded as
+// well, its result is returned together with ErrSuperseded.
+// A maxAttempts < 1 means a single attempt.
+//
+// Each attempt holds a Reader while fn is running, so fn should
+// return quickly. The result reflects a single coherent generation,
+// which was the newest one when fn returned.
+//
+// Calling ReadStable is threadsafe.
+func ReadStable[T, R any](w *Writer[T], maxAttempts int, fn func(T) (R, error)) (R, error) {
+	for attempt := 1; ; attempt++ {
+		r := w.Reader()
+		result, err := fn(r.Get())
+		superseded := w.current.Load() != r.current
+		r.Done()
+		if !superseded {
+			return result, err
+		}
+		if attempt >= maxAttempts {
+			return result, ErrSuperseded
+		}
+	}
+}
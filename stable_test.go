@@ -0,0 +1,38 @@
+package readerwriter
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestReadStable(t *testing.T) {
+	w := New(0, 1)
+	var swaps []<-chan struct{}
+	defer func() {
+		for _, done := range swaps {
+			<-done
+		}
+	}()
+	attempts := 0
+	v, err := ReadStable(w, 3, func(v int) (int, error) {
+		attempts++
+		if attempts == 1 {
+			swaps = append(swaps, w.SwapAsync())
+		}
+		return v, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != 1 || attempts != 2 {
+		t.Fatalf("got %d after %d attempts, want 1 after 2", v, attempts)
+	}
+
+	_, err = ReadStable(w, 2, func(v int) (int, error) {
+		swaps = append(swaps, w.SwapAsync())
+		return v, nil
+	})
+	if !errors.Is(err, ErrSuperseded) {
+		t.Fatalf("got %v, want %v", err, ErrSuperseded)
+	}
+}
@@ -0,0 +1,75 @@
+package readerwriter
+
+// Staged is a Writer with a third portion, the staging portion,
+// between the writer and the reader portion:
+//
+//	writer --PromoteStaging--> staging --Swap--> reader
+//
+// The writer portion can be prepared while the staging portion
+// waits for its publication. PromoteStaging exchanges the writer
+// and the staging portion. Swap publishes the staging portion and
+// the old reader portion becomes the staging portion, once all
+// old Reader's are done. The writer portion is not touched by Swap.
+//
+// Like Writer, only a single goroutine may call the methods of
+// Staged, except Reader.
+type Staged[T any] struct {
+	w      *Writer[T]
+	writer T
+}
+
+// NewStaged returns a new Staged with the specified portions,
+// which must not share memory. opts are applied to the underlying
+// Writer, whose writer portion is the staging portion.
+func NewStaged[T any](reader, staging, writer T, opts ...Option[T]) *Staged[T] {
+	return &Staged[T]{w: New(reader, staging, opts...), writer: writer}
+}
+
+// Writer returns the underlying Writer, which publishes
+// the staging portion.
+func (s *Staged[T]) Writer() *Writer[T] {
+	return s.w
+}
+
+// Reader is like Writer.Reader.
+//
+// Calling Reader is threadsafe.
+func (s *Staged[T]) Reader() *Reader[T] {
+	return s.w.Reader()
+}
+
+// Get returns the writer portion.
+func (s *Staged[T]) Get() T {
+	s.w.lockWriter()
+	defer s.w.unlockWriter()
+	return s.writer
+}
+
+// Set sets the writer portion.
+func (s *Staged[T]) Set(v T) (previous T) {
+	s.w.lockWriter()
+	defer s.w.unlockWriter()
+	previous = s.writer
+	s.writer = v
+	return previous
+}
+
+// Staging returns the staging portion.
+func (s *Staged[T]) Staging() T {
+	return s.w.Get()
+}
+
+// PromoteStaging makes the writer portion the staging portion,
+// which is published by the next Swap. The previous staging
+// portion becomes the writer portion.
+func (s *Staged[T]) PromoteStaging() {
+	s.w.lockWriter()
+	defer s.w.unlockWriter()
+	s.writer, s.w.writerValue = s.w.writerValue, s.writer
+}
+
+// Swap publishes the staging portion like Writer.Swap.
+// Afterwards the old reader portion is the staging portion.
+func (s *Staged[T]) Swap() {
+	s.w.Swap()
+}
@@ -0,0 +1,33 @@
+package readerwriter
+
+import "testing"
+
+func TestStaged(t *testing.T) {
+	s := NewStaged("reader", "staging", "writer")
+	read := func() string {
+		r := s.Reader()
+		defer r.Done()
+		return r.Get()
+	}
+
+	s.Swap()
+	if got := read(); got != "staging" {
+		t.Fatalf("published %q, want %q", got, "staging")
+	}
+	if got := s.Staging(); got != "reader" {
+		t.Fatalf("staging %q, want %q", got, "reader")
+	}
+
+	s.PromoteStaging()
+	if got, want := s.Get()+" "+s.Staging(), "reader writer"; got != want {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	s.Set("next")
+	s.Swap()
+	if got := read(); got != "writer" {
+		t.Fatalf("published %q, want %q", got, "writer")
+	}
+	if got := s.Get(); got != "next" {
+		t.Fatalf("writer %q, want %q", got, "next")
+	}
+}
@@ -0,0 +1,110 @@
+package readerwriter
+
+import (
+	"math/bits"
+	"sync/atomic"
+)
+
+// Stats contains counters describing the history of a Writer.
+type Stats struct {
+	// Swaps is the number of swaps that waited for the old Reader's.
+	Swaps uint64
+	// BlockedSwaps is the number of those swaps that could not
+	// proceed immediately, because old Reader's were still active.
+	BlockedSwaps uint64
+	// TotalBytesCopied is the sum of the bytes reported by
+	// the copy function of NewWithCopyN.
+	TotalBytesCopied uint64
+	// BudgetDrops is the number of retained values,
+	// which were dropped because of WithMemoryBudget.
+	BudgetDrops uint64
+	// AcquireLatency records in nanoseconds how long Reader and
+	// ReaderContext took, if they had to retry because of a Swap.
+	// Acquisitions succeeding at the first attempt are not recorded.
+	AcquireLatency Histogram
+	// WritesPerSwap records the number of writes with Set and
+	// SetIfChanged between two swaps, e.g. to tune the swap
+	// frequency. Modifications of the value returned by Get
+	// are not counted.
+	WritesPerSwap Histogram
+}
+
+// Histogram counts observations in buckets of powers of two.
+// Bucket 0 counts values <= 0, bucket i > 0 counts
+// values v with 1<<(i-1) <= v < 1<<i.
+type Histogram [65]uint64
+
+// Count returns the total number of observations.
+func (h Histogram) Count() uint64 {
+	var n uint64
+	for _, c := range h {
+		n += c
+	}
+	return n
+}
+
+type histogram [65]atomic.Uint64
+
+func (h *histogram) observe(v int64) {
+	if v <= 0 {
+		h[0].Add(1)
+		return
+	}
+	h[bits.Len64(uint64(v))].Add(1)
+}
+
+func (h *histogram) load() Histogram {
+	var s Histogram
+	for i := range h {
+		s[i] = h[i].Load()
+	}
+	return s
+}
+
+func (h *histogram) reset() Histogram {
+	var s Histogram
+	for i := range h {
+		s[i] = h[i].Swap(0)
+	}
+	return s
+}
+
+type stats struct {
+	swaps            atomic.Uint64
+	blockedSwaps     atomic.Uint64
+	totalBytesCopied atomic.Uint64
+	budgetDrops      atomic.Uint64
+	acquireLatency   histogram
+	writesPerSwap    histogram
+}
+
+// Stats returns a snapshot of the counters of the Writer.
+//
+// Calling Stats is threadsafe.
+func (w *Writer[T]) Stats() Stats {
+	return Stats{
+		Swaps:            w.stats.swaps.Load(),
+		BlockedSwaps:     w.stats.blockedSwaps.Load(),
+		TotalBytesCopied: w.stats.totalBytesCopied.Load(),
+		BudgetDrops:      w.stats.budgetDrops.Load(),
+		AcquireLatency:   w.stats.acquireLatency.load(),
+		WritesPerSwap:    w.stats.writesPerSwap.load(),
+	}
+}
+
+// StatsAndReset is like Stats, but zeroes the counters at the same
+// time, e.g. to report deltas periodically. Every event is counted
+// by exactly one call. The snapshot is consistent per counter, but
+// not across counters, like with Stats.
+//
+// Calling StatsAndReset is threadsafe.
+func (w *Writer[T]) StatsAndReset() Stats {
+	return Stats{
+		Swaps:            w.stats.swaps.Swap(0),
+		BlockedSwaps:     w.stats.blockedSwaps.Swap(0),
+		TotalBytesCopied: w.stats.totalBytesCopied.Swap(0),
+		BudgetDrops:      w.stats.budgetDrops.Swap(0),
+		AcquireLatency:   w.stats.acquireLatency.reset(),
+		WritesPerSwap:    w.stats.writesPerSwap.reset(),
+	}
+}
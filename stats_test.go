@@ -0,0 +1,48 @@
+package readerwriter
+
+import "testing"
+
+func TestHistogram(t *testing.T) {
+	var h histogram
+	for _, v := range []int64{-1, 0, 1, 2, 3, 4, 1 << 62} {
+		h.observe(v)
+	}
+	got := h.load()
+	want := Histogram{0: 2, 1: 1, 2: 2, 3: 1, 63: 1}
+	if got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got.Count() != 7 {
+		t.Fatalf("count: got %d, want 7", got.Count())
+	}
+}
+
+func TestStatsAndReset(t *testing.T) {
+	w := New(0, 0)
+	w.Swap()
+	w.Swap()
+	if got := w.StatsAndReset().Swaps; got != 2 {
+		t.Fatalf("got %d swaps, want 2", got)
+	}
+	w.Swap()
+	if got := w.StatsAndReset().Swaps; got != 1 {
+		t.Fatalf("got %d swaps after reset, want 1", got)
+	}
+	if got := w.Stats(); got != (Stats{}) {
+		t.Fatalf("got %+v after reset, want zero", got)
+	}
+}
+
+func TestWritesPerSwap(t *testing.T) {
+	w := New(0, 0)
+	for i := 0; i < 3; i++ {
+		w.Set(i)
+	}
+	w.Swap()
+	w.Swap()
+	h := w.Stats().WritesPerSwap
+	// 3 writes fall into bucket 2, no writes into bucket 0.
+	if h.Count() != 2 || h[2] != 1 || h[0] != 1 {
+		t.Fatalf("unexpected histogram %v", h)
+	}
+}
@@ -0,0 +1,74 @@
+package readerwriter
+
+import "sync"
+
+// subscribeBuffer is the channel capacity of SubscribeDiff.
+const subscribeBuffer = 16
+
+// SubscribeDiff returns a channel, which receives diff(old, new)
+// for every later publication, where old is the previously published
+// value and new the newly published one. This includes TryFastPublish,
+// ReplaceBuffers and BeginSwap; PendingSwap.Rollback sends the diff
+// back to the restored value.
+//
+// diff runs on the writer goroutine during the swap, before the
+// old Reader's are waited for, so its cost is added to every swap.
+// The send blocks the swap once the channel buffer is full, until
+// the consumer receives or calls cancel. A consumer that stops
+// reading must call cancel, otherwise the writer blocks forever.
+// diff must not modify its arguments or retain them.
+//
+// cancel unblocks a pending send, closes the channel and removes
+// the subscriber on the next publication. Calling cancel is
+// threadsafe and more than once is fine.
+//
+// SubscribeDiff must be called by the writer goroutine, like Swap.
+func SubscribeDiff[T, D any](w *Writer[T], diff func(old, new T) D) (diffs <-chan D, cancel func()) {
+	w.lockWriter()
+	defer w.unlockWriter()
+	ch := make(chan D, subscribeBuffer)
+	canceled := make(chan struct{})
+	var (
+		// mu excludes closing ch from a send.
+		mu     sync.Mutex
+		closed bool
+		once   sync.Once
+	)
+	w.subscribers = append(w.subscribers, func(old, new T) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if closed {
+			return false
+		}
+		select {
+		case ch <- diff(old, new):
+		case <-canceled:
+		}
+		return true
+	})
+	cancel = func() {
+		once.Do(func() {
+			close(canceled)
+			mu.Lock()
+			defer mu.Unlock()
+			closed = true
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+// notifySubscribers sends to all subscribers
+// and removes the canceled ones.
+func (w *Writer[T]) notifySubscribers(old, new T) {
+	subscribers := w.subscribers[:0]
+	for _, fn := range w.subscribers {
+		if fn(old, new) {
+			subscribers = append(subscribers, fn)
+		}
+	}
+	for i := len(subscribers); i < len(w.subscribers); i++ {
+		w.subscribers[i] = nil
+	}
+	w.subscribers = subscribers
+}
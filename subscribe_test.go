@@ -0,0 +1,79 @@
+package readerwriter
+
+import "testing"
+
+func TestSubscribeDiff(t *testing.T) {
+	w := New(0, 0)
+	diffs, cancel := SubscribeDiff(w, func(old, new int) int { return new - old })
+	defer cancel()
+
+	w.Set(3)
+	w.Swap()
+	w.Set(10)
+	w.Swap()
+
+	for _, want := range []int{3, 7} {
+		if got := <-diffs; got != want {
+			t.Fatalf("got diff %d, want %d", got, want)
+		}
+	}
+}
+
+func TestSubscribeDiffAllPublications(t *testing.T) {
+	w := New(0, 0)
+	diffs, cancel := SubscribeDiff(w, func(old, new int) [2]int { return [2]int{old, new} })
+	defer cancel()
+
+	if !w.TryFastPublish(1) {
+		t.Fatal("fast publish without readers failed")
+	}
+	w.ReplaceBuffers(2, 3)
+	w.Set(4)
+	w.Swap()
+	w.Set(5)
+	w.BeginSwap().Commit()
+	w.Set(6)
+	if err := w.BeginSwap().Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][2]int{{0, 1}, {1, 2}, {2, 4}, {4, 5}, {5, 6}, {6, 5}}
+	for _, d := range want {
+		if got := <-diffs; got != d {
+			t.Fatalf("got diff %v, want %v", got, d)
+		}
+	}
+	select {
+	case d := <-diffs:
+		t.Fatalf("unexpected diff %v", d)
+	default:
+	}
+}
+
+func TestSubscribeDiffCancel(t *testing.T) {
+	w := New(0, 0)
+	diffs, cancel := SubscribeDiff(w, func(old, new int) int { return new })
+
+	swapped := make(chan struct{})
+	go func() {
+		defer close(swapped)
+		for i := 1; i <= 2*subscribeBuffer; i++ {
+			w.Set(i)
+			w.Swap()
+		}
+	}()
+	// the consumer stops reading after the first diff.
+	if got := <-diffs; got != 1 {
+		t.Fatalf("got diff %d, want 1", got)
+	}
+	cancel()
+	cancel()
+	<-swapped
+	for range diffs {
+	}
+
+	w.Swap()
+	if n := len(w.subscribers); n != 0 {
+		t.Fatalf("got %d subscribers after cancel, want 0", n)
+	}
+}
@@ -0,0 +1,60 @@
+package readerwriter
+
+import (
+	"sync"
+	"time"
+)
+
+// swapRingSize is the number of swaps remembered for SwapRate.
+const swapRingSize = 256
+
+type swapRing struct {
+	mu    sync.Mutex
+	times [swapRingSize]time.Time
+	next  int
+	n     int
+}
+
+func (s *swapRing) add(t time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.times[s.next] = t
+	s.next = (s.next + 1) % swapRingSize
+	if s.n < swapRingSize {
+		s.n++
+	}
+}
+
+func (s *swapRing) rate(now time.Time, window time.Duration) float64 {
+	if window <= 0 {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for i := 1; i <= s.n; i++ {
+		t := s.times[(s.next-i+swapRingSize)%swapRingSize]
+		if now.Sub(t) >= window {
+			break
+		}
+		count++
+	}
+	if count == swapRingSize {
+		// the window holds more swaps than remembered,
+		// so extrapolate from the remembered ones.
+		oldest := s.times[s.next]
+		if span := now.Sub(oldest); span > 0 {
+			return float64(count) / span.Seconds()
+		}
+	}
+	return float64(count) / window.Seconds()
+}
+
+// SwapRate returns the number of swaps per second during the
+// trailing window. Only the last 256 swaps are remembered,
+// if all of them are within window the rate is extrapolated.
+//
+// Calling SwapRate is threadsafe.
+func (w *Writer[T]) SwapRate(window time.Duration) float64 {
+	return w.swapRing.rate(w.clock.Now(), window)
+}
@@ -0,0 +1,29 @@
+package readerwriter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSwapRing(t *testing.T) {
+	var s swapRing
+	start := time.Unix(0, 0)
+	for i := 0; i < 10; i++ {
+		s.add(start.Add(time.Duration(i) * 100 * time.Millisecond))
+	}
+	now := start.Add(time.Second)
+	if got := s.rate(now, time.Second); got != 9 {
+		t.Fatalf("got %v, want 9", got)
+	}
+	if got := s.rate(now, 10*time.Second); got != 1 {
+		t.Fatalf("got %v, want 1", got)
+	}
+
+	for i := 0; i < 2*swapRingSize; i++ {
+		s.add(now.Add(time.Duration(i) * time.Millisecond))
+	}
+	now = now.Add(2 * swapRingSize * time.Millisecond)
+	if got := s.rate(now, time.Hour); got != 1000 {
+		t.Fatalf("got %v, want 1000", got)
+	}
+}
@@ -0,0 +1,86 @@
+package readerwriter
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// SwapResult describes the outcome of a swap, see Writer.SwapE.
+type SwapResult int
+
+const (
+	// Published means the writer portion was published.
+	Published SwapResult = iota
+	// SkippedDedup means the writer portion was equal
+	// to the published value, see WithDedup.
+	SkippedDedup
+	// SkippedValidation means the writer portion was
+	// rejected, see WithValidator.
+	SkippedValidation
+	// SkippedConditional means a condition of the swap did not hold,
+	// e.g. the generation of SwapIfGeneration or the interval of
+	// WithMinSwapInterval with WithSwapCoalescing.
+	SkippedConditional
+	// Suspended means swaps are suspended, see Writer.SuspendSwaps.
+	Suspended
+)
+
+func (r SwapResult) String() string {
+	switch r {
+	case Published:
+		return "published"
+	case SkippedDedup:
+		return "skipped (dedup)"
+	case SkippedValidation:
+		return "skipped (validation)"
+	case SkippedConditional:
+		return "skipped (conditional)"
+	case Suspended:
+		return "suspended"
+	default:
+		return "unknown swap result"
+	}
+}
+
+// SwapInfo describes a finished swap, see WithOnSwap.
+type SwapInfo struct {
+	// Name is the name of the Writer, see WithName.
+	Name string
+	// Generation is the published generation.
+	Generation uint64
+	// Sequence orders the finished swaps of all Writer's in the
+	// process. It increases with every reported swap, so swaps of
+	// different Writer's can be put on a single timeline.
+	Sequence uint64
+	// Waited is the time spent waiting for the old Reader's.
+	Waited time.Duration
+}
+
+// swapSequence is shared by all Writer's, see SwapInfo.Sequence.
+var swapSequence atomic.Uint64
+
+// SwapPhase names the step of a swap, which invoked a callback.
+type SwapPhase string
+
+const (
+	// PhaseValidate is the validation of the writer portion,
+	// see WithValidator.
+	PhaseValidate SwapPhase = "validate"
+)
+
+// SwapError is returned by Writer.SwapE if a callback failed.
+type SwapError struct {
+	Phase SwapPhase
+	// Generation is the generation that was about to be published.
+	Generation uint64
+	Err        error
+}
+
+func (e *SwapError) Error() string {
+	return fmt.Sprintf("swap to generation %d: %s: %v", e.Generation, e.Phase, e.Err)
+}
+
+func (e *SwapError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,22 @@
+package readerwriter
+
+// hookPoint identifies a place in the implementation where tests
+// built with the readerwriter_testhooks tag can interpose, to drive
+// specific interleavings of Reader's and Swap deterministically.
+type hookPoint int
+
+const (
+	// hookReaderLoaded runs after a Reader acquisition loaded
+	// the current generation, before trying to lock it.
+	hookReaderLoaded hookPoint = iota
+	// hookSwapPublished runs after Swap published the new
+	// generation, before waiting for the old Reader's.
+	hookSwapPublished
+	// hookSwapBlocked runs when old Reader's are still active,
+	// before the swap waits for them.
+	hookSwapBlocked
+	// hookSwapDrained runs after all old Reader's are done.
+	hookSwapDrained
+
+	numHookPoints
+)
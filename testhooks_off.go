@@ -0,0 +1,5 @@
+//go:build !readerwriter_testhooks
+
+package readerwriter
+
+func testHook(hookPoint) {}
@@ -0,0 +1,13 @@
+//go:build readerwriter_testhooks
+
+package readerwriter
+
+// testHooks must only be modified while no Reader's or
+// Writer's are in use.
+var testHooks [numHookPoints]func()
+
+func testHook(p hookPoint) {
+	if h := testHooks[p]; h != nil {
+		h()
+	}
+}
@@ -0,0 +1,179 @@
+//go:build readerwriter_testhooks
+
+package readerwriter
+
+import (
+	"context"
+	"testing"
+)
+
+// Run with: go test -tags readerwriter_testhooks
+
+func resetTestHooks() {
+	testHooks = [numHookPoints]func(){}
+}
+
+func TestReaderRetriesAfterSwap(t *testing.T) {
+	defer resetTestHooks()
+
+	w := New(1, 2)
+	loaded, resume := make(chan struct{}), make(chan struct{})
+	loads := 0
+	testHooks[hookReaderLoaded] = func() {
+		loads++
+		if loads == 1 {
+			close(loaded)
+			<-resume
+		}
+	}
+
+	got := make(chan int)
+	go func() {
+		r := w.Reader()
+		got <- r.Get()
+		r.Done()
+	}()
+
+	// the reader loaded generation 1, now swap it out
+	// before the reader gets to lock it.
+	<-loaded
+	w.Swap()
+	close(resume)
+
+	if v := <-got; v != 2 {
+		t.Fatalf("got %d, want 2", v)
+	}
+	if loads != 2 {
+		t.Fatalf("loads: got %d, want 2", loads)
+	}
+	if n := w.Stats().AcquireLatency.Count(); n != 1 {
+		t.Fatalf("acquire latency observations: got %d, want 1", n)
+	}
+}
+
+func TestSwapWaitsForReader(t *testing.T) {
+	defer resetTestHooks()
+
+	w := New(1, 2)
+	published, drained := make(chan struct{}), make(chan struct{})
+	testHooks[hookSwapPublished] = func() { close(published) }
+	testHooks[hookSwapDrained] = func() { close(drained) }
+
+	r := w.Reader()
+	swapped := make(chan struct{})
+	go func() {
+		w.Swap()
+		close(swapped)
+	}()
+
+	<-published
+	newReader := w.Reader()
+	if v := newReader.Get(); v != 2 {
+		t.Fatalf("new reader: got %d, want 2", v)
+	}
+	newReader.Done()
+	select {
+	case <-drained:
+		t.Fatal("swap finished while a reader was active")
+	default:
+	}
+	if v := r.Get(); v != 1 {
+		t.Fatalf("old reader: got %d, want 1", v)
+	}
+
+	r.Done()
+	<-drained
+	<-swapped
+}
+
+func TestTryReaderDuringSwap(t *testing.T) {
+	defer resetTestHooks()
+
+	w := New(1, 2)
+	holder := w.Reader()
+	loaded, resume := make(chan struct{}), make(chan struct{})
+	testHooks[hookReaderLoaded] = func() {
+		close(loaded)
+		<-resume
+	}
+	type result struct {
+		r  *Reader[int]
+		ok bool
+	}
+	got := make(chan result)
+	go func() {
+		r, ok := w.TryReader()
+		got <- result{r, ok}
+	}()
+	<-loaded
+
+	// TryReader fails whether Swap already waits in Lock or not,
+	// because the new generation is published at that point.
+	blocked := make(chan struct{})
+	testHooks[hookSwapBlocked] = func() { close(blocked) }
+	swapped := make(chan struct{})
+	go func() {
+		w.Swap()
+		close(swapped)
+	}()
+	<-blocked
+	close(resume)
+	if res := <-got; res.ok || res.r != nil {
+		t.Fatal("TryReader succeeded on a generation being drained")
+	}
+
+	holder.Done()
+	<-swapped
+}
+
+func TestBackoff(t *testing.T) {
+	defer resetTestHooks()
+
+	var attempts []int
+	w := New(1, 2, WithBackoff[int](BackoffFunc(func(attempt int, _ TimeSource) {
+		attempts = append(attempts, attempt)
+	})))
+	loaded, resume := make(chan struct{}), make(chan struct{})
+	loads := 0
+	testHooks[hookReaderLoaded] = func() {
+		loads++
+		if loads == 1 {
+			close(loaded)
+			<-resume
+		}
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.Reader().Done()
+	}()
+	<-loaded
+	w.Swap()
+	close(resume)
+	<-done
+
+	if len(attempts) != 1 || attempts[0] != 0 {
+		t.Fatalf("attempts: got %v, want [0]", attempts)
+	}
+}
+
+func TestOnReaderTimeout(t *testing.T) {
+	defer resetTestHooks()
+
+	churn := -1
+	w := New(1, 2, WithOnReaderTimeout[int](func(generationChurn int) { churn = generationChurn }))
+	ctx, cancel := context.WithCancel(context.Background())
+	testHooks[hookReaderLoaded] = func() {
+		testHooks[hookReaderLoaded] = nil
+		// swap the loaded generation out and give up.
+		w.Swap()
+		cancel()
+	}
+	if _, err := w.ReaderContext(ctx); err != context.Canceled {
+		t.Fatalf("got %v, want context.Canceled", err)
+	}
+	if churn != 1 {
+		t.Fatalf("churn: got %d, want 1", churn)
+	}
+}
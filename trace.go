@@ -0,0 +1,58 @@
+package readerwriter
+
+// GenerationEventKind is the kind of a GenerationEvent.
+type GenerationEventKind int
+
+const (
+	// GenerationCreated means a new generation was created
+	// from the writer portion.
+	GenerationCreated GenerationEventKind = iota
+	// GenerationPublished means the generation became visible to Reader's.
+	GenerationPublished
+	// GenerationRetired means the generation was replaced by another
+	// one. Its Reader's might still be active.
+	GenerationRetired
+	// GenerationReused means the buffer of the retired generation
+	// became the writer portion.
+	GenerationReused
+)
+
+func (k GenerationEventKind) String() string {
+	switch k {
+	case GenerationCreated:
+		return "created"
+	case GenerationPublished:
+		return "published"
+	case GenerationRetired:
+		return "retired"
+	case GenerationReused:
+		return "reused"
+	default:
+		return "unknown generation event"
+	}
+}
+
+// GenerationEvent is passed to the WithGenerationTrace hook.
+type GenerationEvent struct {
+	Kind       GenerationEventKind
+	Generation uint64
+	// Buffer identifies the buffer holding the value of the
+	// generation. The reader and writer portion passed to New
+	// are buffer 0 and 1. Set does not change the identity.
+	Buffer uint64
+}
+
+// WithGenerationTrace calls fn with the lifecycle events of the
+// generations, which helps to understand which buffer is which.
+// fn runs on the writer goroutine.
+func WithGenerationTrace[T any](fn func(GenerationEvent)) Option[T] {
+	return runtimeOption(func(w *Writer[T]) {
+		w.traceFn = fn
+	})
+}
+
+func (w *Writer[T]) trace(kind GenerationEventKind, c *current[T]) {
+	if w.traceFn != nil && w.traceSampler.sampleGeneration(c.generation.Load()) {
+		w.traceFn(GenerationEvent{Kind: kind, Generation: c.generation.Load(), Buffer: c.buffer})
+	}
+}
@@ -0,0 +1,31 @@
+package readerwriter
+
+import "testing"
+
+func TestGenerationTrace(t *testing.T) {
+	var events []GenerationEvent
+	w := New(0, 1, WithGenerationTrace[int](func(e GenerationEvent) {
+		events = append(events, e)
+	}))
+	w.Swap()
+	w.Swap()
+
+	want := []GenerationEvent{
+		{GenerationCreated, 1, 1},
+		{GenerationPublished, 1, 1},
+		{GenerationRetired, 0, 0},
+		{GenerationReused, 0, 0},
+		{GenerationCreated, 2, 0},
+		{GenerationPublished, 2, 0},
+		{GenerationRetired, 1, 1},
+		{GenerationReused, 1, 1},
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %v, want %v", events, want)
+	}
+	for i := range want {
+		if events[i] != want[i] {
+			t.Fatalf("event %d: got %v, want %v", i, events[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package readerwriter
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// ReaderWithTTL is like Reader, but the Reader is released
+// automatically, if Done was not called within d. The release
+// is reported to the WithLeakDetection hook or logged, as it
+// is considered a leak, e.g. from a hanging code path. If Renew
+// acquires the published generation, d starts again.
+//
+// After the release the Reader is done: Get and the other methods
+// panic and Done does nothing. The value of the Reader becomes
+// invalid at that point, even if the caller is still using it,
+// because the next Swap reuses it for the writer portion. Hence d
+// must be far longer than any legitimate read.
+//
+// Calling ReaderWithTTL is threadsafe.
+func (w *Writer[T]) ReaderWithTTL(d time.Duration) *Reader[T] {
+	r := w.Reader()
+	r.ttl = &readerTTL{d: d}
+	r.ttl.mu.Lock()
+	r.startTTL()
+	r.ttl.mu.Unlock()
+	return r
+}
+
+// readerTTL is the timer of a Reader from ReaderWithTTL.
+// mu excludes the release by the timer from Done, Renew
+// and SetUserData.
+type readerTTL struct {
+	mu      sync.Mutex
+	d       time.Duration
+	stop    func() bool
+	armed   uint64
+	expired bool
+}
+
+// startTTL arms the timer for the current generation of r.
+// A timer that fired before a later startTTL does nothing.
+// r.ttl.mu must be held.
+func (r *Reader[T]) startTTL() {
+	w, t := r.w, r.ttl
+	t.armed++
+	armed := t.armed
+	t.stop = w.clock.AfterFunc(t.d, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if t.armed != armed || r.done.Load() {
+			return
+		}
+		info := w.readerInfo(r)
+		if w.leakFn != nil {
+			w.leakFn(info)
+		} else {
+			log.Printf("readerwriter: %q: reader of generation %d released after %v\n%s", w.name, info.Generation, t.d, info.Stack)
+		}
+		t.expired = r.finish()
+	})
+}
@@ -0,0 +1,76 @@
+package readerwriter
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReaderWithTTL(t *testing.T) {
+	leaked := make(chan ReaderInfo, 1)
+	w := New(0, 0, WithLeakDetection[int](func(info ReaderInfo) { leaked <- info }))
+	r := w.ReaderWithTTL(time.Millisecond)
+	if info := <-leaked; info.Generation != 0 {
+		t.Fatalf("got generation %d, want 0", info.Generation)
+	}
+	w.Swap()
+	r.Done()
+
+	defer func() {
+		if p := recover(); p != messageUsageOldReaderDetected {
+			t.Fatalf("got panic %v, want %q", p, messageUsageOldReaderDetected)
+		}
+	}()
+	r.Get()
+}
+
+func TestReaderWithTTLDone(t *testing.T) {
+	w := New(0, 0, WithLeakDetection[int](func(ReaderInfo) { t.Error("reader released by TTL") }))
+	r := w.ReaderWithTTL(time.Hour)
+	r.Done()
+	if n := w.ActiveReaders(); n != 0 {
+		t.Fatalf("got %d active readers, want 0", n)
+	}
+	w.Swap()
+}
+
+func TestReaderWithTTLRenew(t *testing.T) {
+	w := New(0, 0, WithLeakDetection[int](func(ReaderInfo) {}))
+	r := w.ReaderWithTTL(50 * time.Microsecond)
+	swapped := make(chan struct{})
+	go func() {
+		defer close(swapped)
+		for i := 0; i < 100; i++ {
+			w.Swap()
+		}
+	}()
+
+	renew := func(i int) (expired bool) {
+		defer func() {
+			if p := recover(); p != nil {
+				if p != messageUsageOldReaderDetected {
+					panic(p)
+				}
+				expired = true
+			}
+		}()
+		r.Renew()
+		r.SetUserData(i)
+		return false
+	}
+	for i := 0; ; i++ {
+		select {
+		case <-swapped:
+			r.Done()
+			if n := w.ActiveReaders(); n != 0 {
+				t.Fatalf("got %d active readers, want 0", n)
+			}
+			return
+		default:
+		}
+		if renew(i) {
+			r.Done()
+			<-swapped
+			return
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package readerwriter
+
+// Unsync has the API of Writer for the basic operations, but
+// does not synchronize at all, e.g. for a CLI building an index
+// on a single goroutine. Code using it can be switched to
+// Writer later with few changes.
+//
+// Unsync is NOT SAFE FOR CONCURRENT USE, not even of its Reader's.
+// Concurrent calls are detected on a best-effort basis only
+// and panic. Use Writer as soon as more than one goroutine
+// is involved.
+type Unsync[T any] struct {
+	reader, writer T
+	copy           func(dst, src T)
+	generation     Generation
+	readers        int
+	// busy is set while a method runs, to detect concurrent use.
+	// It is intentionally not synchronized.
+	busy bool
+}
+
+// NewUnsync is like New, but returns an Unsync.
+func NewUnsync[T any](reader, writer T) *Unsync[T] {
+	return &Unsync[T]{reader: reader, writer: writer}
+}
+
+// NewUnsyncWithCopy is like NewWithCopy, but returns an Unsync.
+func NewUnsyncWithCopy[T any](reader, writer T, copy func(dst, src T)) *Unsync[T] {
+	return &Unsync[T]{reader: reader, writer: writer, copy: copy}
+}
+
+func (u *Unsync[T]) enter() {
+	if u.busy {
+		panic(messageMultipleWritersDetected)
+	}
+	u.busy = true
+}
+
+func (u *Unsync[T]) exit() {
+	u.busy = false
+}
+
+// Get returns the current writer portion, like Writer.Get.
+func (u *Unsync[T]) Get() T {
+	u.enter()
+	defer u.exit()
+	return u.writer
+}
+
+// Set sets the current writer portion, like Writer.Set.
+func (u *Unsync[T]) Set(v T) (previous T) {
+	u.enter()
+	defer u.exit()
+	previous = u.writer
+	u.writer = v
+	return previous
+}
+
+// Swap exchanges the reader and writer portion and copies the new
+// reader portion to the new writer portion, if a copy function is
+// registered, like Writer.Swap. It panics if a Reader is not done,
+// because the Swap of a Writer would wait for it forever.
+func (u *Unsync[T]) Swap() {
+	u.enter()
+	defer u.exit()
+	if u.readers != 0 {
+		panic(messageSwapWouldDeadlock)
+	}
+	u.reader, u.writer = u.writer, u.reader
+	u.generation++
+	if u.copy != nil {
+		u.copy(u.writer, u.reader)
+	}
+}
+
+// Reader returns a Reader of the reader portion, like Writer.Reader.
+func (u *Unsync[T]) Reader() *UnsyncReader[T] {
+	u.enter()
+	defer u.exit()
+	u.readers++
+	return &UnsyncReader[T]{u: u, generation: u.generation}
+}
+
+// Read calls fn with the reader portion, like Writer.Read.
+func (u *Unsync[T]) Read(fn func(T)) {
+	r := u.Reader()
+	defer r.Done()
+	fn(r.Get())
+}
+
+// UnsyncReader is the Reader of an Unsync.
+// It reads the reader portion of the Unsync directly.
+type UnsyncReader[T any] struct {
+	u          *Unsync[T]
+	generation Generation
+	done       bool
+}
+
+// Get returns the reader portion, like Reader.Get.
+func (r *UnsyncReader[T]) Get() T {
+	if r.done {
+		panic(messageUsageOldReaderDetected)
+	}
+	return r.u.reader
+}
+
+// Generation returns the generation of the reader portion,
+// like Reader.Generation.
+func (r *UnsyncReader[T]) Generation() Generation {
+	if r.done {
+		panic(messageUsageOldReaderDetected)
+	}
+	return r.generation
+}
+
+// Done must be called when finished reading, like Reader.Done.
+func (r *UnsyncReader[T]) Done() {
+	if r.done {
+		panic(messageUsageOldReaderDetected)
+	}
+	r.done = true
+	r.u.readers--
+}
@@ -0,0 +1,28 @@
+package readerwriter
+
+import "testing"
+
+func TestUnsync(t *testing.T) {
+	u := NewUnsyncWithCopy(map[string]int{}, map[string]int{}, copyMap)
+	u.Get()["a"] = 1
+	u.Swap()
+	var got int
+	u.Read(func(m map[string]int) { got = m["a"] })
+	if got != 1 {
+		t.Fatalf("got %d, want 1", got)
+	}
+	if u.Get()["a"] != 1 {
+		t.Fatal("writer portion not copied")
+	}
+
+	r := u.Reader()
+	if r.Generation() != 1 {
+		t.Fatalf("got generation %d, want 1", r.Generation())
+	}
+	defer func() {
+		if p := recover(); p != messageSwapWouldDeadlock {
+			t.Fatalf("got panic %v, want %q", p, messageSwapWouldDeadlock)
+		}
+	}()
+	u.Swap()
+}
@@ -0,0 +1,114 @@
+package readerwriter
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNotInitialized is returned by Writer.ReadNonZero,
+// if no value was published yet.
+var ErrNotInitialized = errors.New("no value published yet")
+
+// WaitUntil blocks until pred reports true for the published value.
+// pred is evaluated immediately and again after every publication,
+// while holding a Reader, so it should return quickly.
+//
+// Calling WaitUntil is threadsafe, but it must not be called by the
+// writer goroutine, which would never publish a new value.
+func (w *Writer[T]) WaitUntil(pred func(T) bool) {
+	w.WaitUntilContext(context.Background(), pred)
+}
+
+// WaitUntilContext is like WaitUntil, but returns ctx.Err()
+// if ctx is done before pred reports true.
+func (w *Writer[T]) WaitUntilContext(ctx context.Context, pred func(T) bool) error {
+	return w.waitPublished(ctx, func() bool { return w.holds(pred) })
+}
+
+// WaitForGeneration blocks until the published generation
+// is at least generation (see Reader.Generation).
+//
+// Calling WaitForGeneration is threadsafe, but it must not be
+// called by the writer goroutine, like WaitUntil.
+func (w *Writer[T]) WaitForGeneration(generation Generation) {
+	w.WaitForGenerationContext(context.Background(), generation)
+}
+
+// WaitForGenerationContext is like WaitForGeneration, but returns
+// ctx.Err() if ctx is done before the generation is published.
+func (w *Writer[T]) WaitForGenerationContext(ctx context.Context, generation Generation) error {
+	return w.waitPublished(ctx, func() bool { return w.CurrentGeneration() >= generation })
+}
+
+// waitPublished waits until cond reports true. cond is evaluated
+// again after every publication, which wakes up all waiters with
+// a single broadcast. cond acquires a Reader, which might block
+// (e.g. during StopAdmitting), so it is evaluated without holding
+// publishedMu, otherwise the next publication would block too.
+// Instead the publications counted before evaluating cond tell
+// whether one was missed in between.
+func (w *Writer[T]) waitPublished(ctx context.Context, cond func() bool) error {
+	if ctx.Done() != nil {
+		stop := make(chan struct{})
+		defer close(stop)
+		go func() {
+			select {
+			case <-ctx.Done():
+				w.broadcastPublished()
+			case <-stop:
+			}
+		}()
+	}
+
+	for {
+		w.publishedMu.Lock()
+		seen := w.publications
+		w.publishedMu.Unlock()
+		if cond() {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		w.publishedMu.Lock()
+		for w.publications == seen && ctx.Err() == nil {
+			w.published.Wait()
+		}
+		w.publishedMu.Unlock()
+	}
+}
+
+// ReadNonZero returns the published value, or ErrNotInitialized
+// if isZero reports that it is still the initial zero value, e.g.
+// of a configuration that was not loaded yet. To wait for the first
+// value instead, use WaitUntil with the negation of isZero.
+//
+// The Reader is released before returning, so the result is only
+// safe to use if T does not share memory with the reader portion,
+// like with ReadOrStale.
+//
+// Calling ReadNonZero is threadsafe.
+func (w *Writer[T]) ReadNonZero(isZero func(T) bool) (T, error) {
+	r := w.Reader()
+	defer r.Done()
+	v := r.Get()
+	if isZero(v) {
+		return v, ErrNotInitialized
+	}
+	return v, nil
+}
+
+func (w *Writer[T]) holds(pred func(T) bool) bool {
+	r := w.Reader()
+	defer r.Done()
+	return pred(r.Get())
+}
+
+// broadcastPublished wakes up the goroutines waiting
+// for a new published value.
+func (w *Writer[T]) broadcastPublished() {
+	w.publishedMu.Lock()
+	w.publications++
+	w.published.Broadcast()
+	w.publishedMu.Unlock()
+}
@@ -0,0 +1,131 @@
+package readerwriter
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWaitUntil(t *testing.T) {
+	w := New(0, 0)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.WaitUntil(func(v int) bool { return v >= 3 })
+	}()
+	for i := 1; i <= 3; i++ {
+		w.Set(i)
+		w.Swap()
+	}
+	<-done
+}
+
+func TestWaitUntilContext(t *testing.T) {
+	w := New(0, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := w.WaitUntilContext(ctx, func(v int) bool { return v != 0 })
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+	if err := w.WaitUntilContext(context.Background(), func(v int) bool { return v == 0 }); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReadNonZero(t *testing.T) {
+	w := New("", "")
+	isZero := func(v string) bool { return v == "" }
+	if _, err := w.ReadNonZero(isZero); !errors.Is(err, ErrNotInitialized) {
+		t.Fatalf("got %v, want %v", err, ErrNotInitialized)
+	}
+	w.Set("config")
+	w.Swap()
+	if v, err := w.ReadNonZero(isZero); err != nil || v != "config" {
+		t.Fatalf("got %q, %v, want %q", v, err, "config")
+	}
+}
+
+func TestWaitForGeneration(t *testing.T) {
+	const generations = 100
+	w := New(0, 0)
+	var wg sync.WaitGroup
+	for i := 0; i < 64; i++ {
+		target := Generation(i % (generations + 1))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.WaitForGeneration(target)
+			if got := w.CurrentGeneration(); got < target {
+				t.Errorf("woke up at generation %d, want at least %d", got, target)
+			}
+		}()
+	}
+	for i := 0; i < generations; i++ {
+		if i%2 == 0 {
+			w.Swap()
+		} else if !w.TryFastPublish(i) {
+			w.Swap()
+		}
+	}
+	wg.Wait()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	if err := w.WaitForGenerationContext(ctx, generations+1); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestWaitForGenerationGroupSwap(t *testing.T) {
+	w := New(0, 0)
+	g := NewGroup()
+	AddToGroup(g, w)
+	waiting := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		w.publishedMu.Lock()
+		close(waiting)
+		w.publishedMu.Unlock()
+		w.WaitForGeneration(1)
+	}()
+	<-waiting
+	g.Swap()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("WaitForGeneration missed the publication of Group.Swap")
+	}
+}
+
+func TestWaitUntilNotAdmitting(t *testing.T) {
+	w := New(0, 0)
+	waiting := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		close(waiting)
+		w.WaitUntil(func(v int) bool { return v == 2 })
+	}()
+	<-waiting
+	w.StopAdmitting()
+
+	swapped := make(chan struct{})
+	go func() {
+		defer close(swapped)
+		w.Set(1)
+		w.Swap()
+		w.Set(2)
+		w.Swap()
+	}()
+	select {
+	case <-swapped:
+	case <-time.After(time.Second):
+		t.Fatal("Swap blocked by a waiter not admitted")
+	}
+	w.ResumeAdmitting()
+	<-done
+}